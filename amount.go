@@ -0,0 +1,391 @@
+package solanastreaming
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxExponent bounds the exponent accepted by ParseDecimal so a malicious frame can not make us allocate huge numbers.
+const maxExponent = 1000
+
+var bigTen = big.NewInt(10)
+
+// RoundingMode decides how a Decimal is rounded when digits are dropped.
+type RoundingMode int
+
+const (
+	RoundDown     RoundingMode = iota // toward zero
+	RoundUp                           // away from zero
+	RoundHalfUp                       // to the nearest neighbour, ties away from zero
+	RoundHalfEven                     // to the nearest neighbour, ties to the even neighbour
+	RoundFloor                        // toward negative infinity
+	RoundCeiling                      // toward positive infinity
+)
+
+// Decimal is an exact decimal number with the value Unscaled × 10^-Scale. The zero value is an unset number, which
+// is treated as zero by arithmetic and marshals to null. Decimals are immutable, operations return new values.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+// NewDecimal returns unscaled × 10^-scale. unscaled is copied.
+func NewDecimal(unscaled *big.Int, scale int32) Decimal {
+	if unscaled == nil {
+		return Decimal{}
+	}
+	if scale < 0 {
+		return Decimal{unscaled: new(big.Int).Mul(unscaled, pow10(-scale))}
+	}
+	return Decimal{unscaled: new(big.Int).Set(unscaled), scale: scale}
+}
+
+// ParseDecimal parses a number such as "-12.50" or "4.0968e-8" exactly. Digits are kept, "1.50" stays "1.50".
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e > maxExponent || e < -maxExponent {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		mantissa, exponent = s[:i], e
+	}
+	whole, fraction, _ := strings.Cut(mantissa, ".")
+	digits := strings.TrimLeft(whole, "+-") + fraction
+	if len(whole)-len(strings.TrimLeft(whole, "+-")) > 1 || digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	unscaled, _ := new(big.Int).SetString(digits, 10)
+	if strings.HasPrefix(whole, "-") {
+		unscaled.Neg(unscaled)
+	}
+	return NewDecimal(unscaled, int32(len(fraction)-exponent)), nil
+}
+
+// MustParseDecimal is ParseDecimal that panics on invalid input, for constants.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// IsSet reports whether the number was set, false for the zero value.
+func (d Decimal) IsSet() bool {
+	return d.unscaled != nil
+}
+
+// Unscaled returns a copy of the unscaled integer value.
+func (d Decimal) Unscaled() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(d.unscaled)
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1.
+func (d Decimal) Sign() int {
+	if d.unscaled == nil {
+		return 0
+	}
+	return d.unscaled.Sign()
+}
+
+// IsZero reports whether d is zero or unset.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp compares d and other and returns -1, 0 or +1.
+func (d Decimal) Cmp(other Decimal) int {
+	a, b := align(d, other)
+	return a.Cmp(b)
+}
+
+// Add returns d + other.
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b := align(d, other)
+	return Decimal{unscaled: a.Add(a, b), scale: max(d.scale, other.scale)}
+}
+
+// Sub returns d - other.
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b := align(d, other)
+	return Decimal{unscaled: a.Sub(a, b), scale: max(d.scale, other.scale)}
+}
+
+// Mul returns d × other exactly.
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.int(), other.int()), scale: d.scale + other.scale}
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Quo returns d / other with scale digits after the decimal point, rounded with mode. It panics if other is zero.
+func (d Decimal) Quo(other Decimal, scale int32, mode RoundingMode) Decimal {
+	if other.IsZero() {
+		panic("solanastreaming: division by zero")
+	}
+	num, den := d.int(), other.int()
+	// d / other = (num / den) × 10^(other.scale - d.scale)
+	if shift := scale - d.scale + other.scale; shift >= 0 {
+		num = new(big.Int).Mul(num, pow10(shift))
+	} else {
+		den = new(big.Int).Mul(den, pow10(-shift))
+	}
+	return Decimal{unscaled: divRound(num, den, mode), scale: scale}
+}
+
+// Round returns d with scale digits after the decimal point, rounded with mode. A larger scale pads with zeros.
+func (d Decimal) Round(scale int32, mode RoundingMode) Decimal {
+	if scale >= d.scale {
+		return Decimal{unscaled: new(big.Int).Mul(d.int(), pow10(scale-d.scale)), scale: scale}
+	}
+	return Decimal{unscaled: divRound(d.int(), pow10(d.scale-scale), mode), scale: scale}
+}
+
+// Shift returns d × 10^n exactly.
+func (d Decimal) Shift(n int32) Decimal {
+	if n <= d.scale {
+		return Decimal{unscaled: d.int(), scale: d.scale - n}
+	}
+	return Decimal{unscaled: new(big.Int).Mul(d.int(), pow10(n-d.scale))}
+}
+
+// Rat returns d as a fraction.
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.int(), pow10(d.scale))
+}
+
+// Float64 returns the nearest float64, for display and rough maths.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// String formats d without exponent, keeping all digits. An unset number formats as "".
+func (d Decimal) String() string {
+	if d.unscaled == nil {
+		return ""
+	}
+	digits := new(big.Int).Abs(d.unscaled).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) - len(digits) + 1; pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		digits = digits[:len(digits)-int(d.scale)] + "." + digits[len(digits)-int(d.scale):]
+	}
+	if d.unscaled.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// MarshalJSON encodes d as a string to keep every digit, an unset number as null.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if d.unscaled == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a string or a number. null and "" leave d unset.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s, err := jsonNumberString(data)
+	if err != nil || s == "" {
+		*d = Decimal{}
+		return err
+	}
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// int returns the unscaled value, zero if unset. The result must not be modified.
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// Amount is a token amount in raw base units as sent by the server, e.g. lamports for wrapped SOL.
+// Use UI or Token.UI to scale it by the token decimals. The zero value means the server sent no amount.
+type Amount struct {
+	value Decimal
+}
+
+// NewAmount returns an amount of raw base units. raw is copied.
+func NewAmount(raw *big.Int) Amount {
+	return Amount{value: NewDecimal(raw, 0)}
+}
+
+// ParseAmount parses a raw amount such as "1500000000" exactly.
+func ParseAmount(s string) (Amount, error) {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		return Amount{}, err
+	}
+	return Amount{value: d}, nil
+}
+
+// IsSet reports whether the server sent the amount.
+func (a Amount) IsSet() bool {
+	return a.value.IsSet()
+}
+
+// IsZero reports whether the amount is zero or unset.
+func (a Amount) IsZero() bool {
+	return a.value.IsZero()
+}
+
+// Int returns the amount as an integer, false if it has a fractional part.
+func (a Amount) Int() (*big.Int, bool) {
+	d := a.value.Round(0, RoundDown)
+	if d.Cmp(a.value) != 0 {
+		return nil, false
+	}
+	return d.Unscaled(), true
+}
+
+// Decimal returns the amount in raw base units.
+func (a Amount) Decimal() Decimal {
+	return a.value
+}
+
+// UI returns the amount in whole tokens for a token with the given decimals, e.g. 1500000000 lamports as 1.5.
+func (a Amount) UI(decimals uint) Decimal {
+	if !a.IsSet() {
+		return Decimal{}
+	}
+	return a.value.Shift(-int32(decimals))
+}
+
+// Cmp compares a and other and returns -1, 0 or +1.
+func (a Amount) Cmp(other Amount) int {
+	return a.value.Cmp(other.value)
+}
+
+// Add returns a + other.
+func (a Amount) Add(other Amount) Amount {
+	return Amount{value: a.value.Add(other.value)}
+}
+
+// Sub returns a - other.
+func (a Amount) Sub(other Amount) Amount {
+	return Amount{value: a.value.Sub(other.value)}
+}
+
+// String formats the amount as sent by the server, "" if unset.
+func (a Amount) String() string {
+	return a.value.String()
+}
+
+// MarshalJSON encodes the amount as a string, "" if unset.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.value.String())
+}
+
+// UnmarshalJSON accepts a string or a number. null and "" leave the amount unset.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	return a.value.UnmarshalJSON(data)
+}
+
+// UI returns amount in whole tokens, false if the token decimals are unknown.
+func (t Token) UI(amount Amount) (Decimal, bool) {
+	if t.Info == nil || !amount.IsSet() {
+		return Decimal{}, false
+	}
+	return amount.UI(t.Info.Decimals), true
+}
+
+// SupplyUI returns the supply in whole tokens, unset if the supply is.
+func (t TokenInfo) SupplyUI() Decimal {
+	return t.Supply.UI(t.Decimals)
+}
+
+// QuoteAmount returns BaseAmount × QuotePrice, the amount of quote token traded, rounded with mode to the precision
+// of BaseAmount. It is unset if the swap has no base amount or price.
+func (s Swap) QuoteAmount(mode RoundingMode) Amount {
+	if !s.BaseAmount.IsSet() || !s.QuotePrice.IsSet() {
+		return Amount{}
+	}
+	base := s.BaseAmount.Decimal()
+	return Amount{value: base.Mul(s.QuotePrice).Round(base.Scale(), mode)}
+}
+
+// jsonNumberString returns the text of a JSON string or number, "" for null.
+func jsonNumberString(data []byte) (string, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return "", nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		err := json.Unmarshal(data, &s)
+		return s, err
+	}
+	return string(data), nil
+}
+
+// align returns the unscaled values of a and b at the larger of both scales.
+func align(a, b Decimal) (*big.Int, *big.Int) {
+	x, y := new(big.Int).Set(a.int()), new(big.Int).Set(b.int())
+	if a.scale < b.scale {
+		x.Mul(x, pow10(b.scale-a.scale))
+	} else if b.scale < a.scale {
+		y.Mul(y, pow10(a.scale-b.scale))
+	}
+	return x, y
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// divRound returns num / den rounded with mode.
+func divRound(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	sign := num.Sign() * den.Sign()
+	away := false
+	switch mode {
+	case RoundUp:
+		away = true
+	case RoundHalfUp, RoundHalfEven:
+		half := new(big.Int).Abs(r)
+		half.Lsh(half, 1)
+		switch c := half.Cmp(new(big.Int).Abs(den)); {
+		case c > 0:
+			away = true
+		case c == 0:
+			away = mode == RoundHalfUp || q.Bit(0) == 1
+		}
+	case RoundFloor:
+		away = sign < 0
+	case RoundCeiling:
+		away = sign > 0
+	}
+	if away {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+	return q
+}
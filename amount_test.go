@@ -0,0 +1,132 @@
+package solanastreaming_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+)
+
+func TestParseDecimal(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{"0", "0"},
+		{"1.50", "1.50"},
+		{"-12.345", "-12.345"},
+		{"+7", "7"},
+		{".5", "0.5"},
+		{"4.0968426273752e-8", "0.000000040968426273752"},
+		{"1.5E3", "1500"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+	} {
+		d, err := solanastreaming.ParseDecimal(tc.in)
+		if err != nil {
+			t.Fatalf("ParseDecimal(%q): %v", tc.in, err)
+		}
+		if got := d.String(); got != tc.want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+	for _, in := range []string{"", "-", ".", "1.2.3", "1e", "--1", "1-2", "abc", "1e100000", "NaN"} {
+		if _, err := solanastreaming.ParseDecimal(in); err == nil {
+			t.Errorf("ParseDecimal(%q) succeeded", in)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	d := solanastreaming.MustParseDecimal
+	if got := d("0.1").Add(d("0.2")); got.Cmp(d("0.3")) != 0 || got.String() != "0.3" {
+		t.Errorf("0.1 + 0.2 = %s", got)
+	}
+	if got := d("1").Sub(d("0.001")).String(); got != "0.999" {
+		t.Errorf("1 - 0.001 = %s", got)
+	}
+	if got := d("1.5").Mul(d("-2.25")).String(); got != "-3.375" {
+		t.Errorf("1.5 × -2.25 = %s", got)
+	}
+	if got := d("1").Quo(d("3"), 4, solanastreaming.RoundHalfUp).String(); got != "0.3333" {
+		t.Errorf("1 / 3 = %s", got)
+	}
+	if got := d("2").Quo(d("0.003"), 2, solanastreaming.RoundDown).String(); got != "666.66" {
+		t.Errorf("2 / 0.003 = %s", got)
+	}
+
+	for _, tc := range []struct {
+		in   string
+		mode solanastreaming.RoundingMode
+		want string
+	}{
+		{"2.5", solanastreaming.RoundHalfEven, "2"},
+		{"3.5", solanastreaming.RoundHalfEven, "4"},
+		{"2.5", solanastreaming.RoundHalfUp, "3"},
+		{"-2.5", solanastreaming.RoundHalfUp, "-3"},
+		{"2.1", solanastreaming.RoundUp, "3"},
+		{"-2.9", solanastreaming.RoundDown, "-2"},
+		{"-2.1", solanastreaming.RoundFloor, "-3"},
+		{"-2.9", solanastreaming.RoundCeiling, "-2"},
+	} {
+		if got := d(tc.in).Round(0, tc.mode).String(); got != tc.want {
+			t.Errorf("Round(%s, %d) = %s, want %s", tc.in, tc.mode, got, tc.want)
+		}
+	}
+}
+
+func TestAmount(t *testing.T) {
+	var pair solanastreaming.Pair
+	data := `{"baseToken":{"info":{"decimals":6,"supply":"1000000000000000"}},"baseTokenLiquidityAdded":"206900000000000","quoteTokenLiquidityAdded":84990359000}`
+	if err := json.Unmarshal([]byte(data), &pair); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if got := pair.BaseToken.Info.SupplyUI().String(); got != "1000000000.000000" {
+		t.Errorf("supply = %s", got)
+	}
+	if ui, ok := pair.BaseToken.UI(pair.BaseTokenLiquidityAdded); !ok || ui.String() != "206900000.000000" {
+		t.Errorf("base liquidity = %s", ui)
+	}
+	if _, ok := pair.QuoteToken.UI(pair.QuoteTokenLiquidityAdded); ok {
+		t.Error("quote token without info scaled")
+	}
+	if got := pair.QuoteTokenLiquidityAdded.UI(9).String(); got != "84.990359000" {
+		t.Errorf("quote liquidity = %s", got)
+	}
+	raw, ok := pair.BaseTokenLiquidityAdded.Int()
+	if want, _ := new(big.Int).SetString("206900000000000", 10); !ok || raw.Cmp(want) != 0 {
+		t.Errorf("raw base liquidity = %s", raw)
+	}
+	if pair.Migration != "" || pair.QuoteToken.Info != nil {
+		t.Errorf("unexpected pair: %+v", pair)
+	}
+
+	out, err := json.Marshal(pair)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var again solanastreaming.Pair
+	if err := json.Unmarshal(out, &again); err != nil || again.QuoteTokenLiquidityAdded.Cmp(pair.QuoteTokenLiquidityAdded) != 0 {
+		t.Fatalf("round trip lost the amount: %s", out)
+	}
+}
+
+func TestSwapQuoteAmount(t *testing.T) {
+	var swap solanastreaming.Swap
+	if err := json.Unmarshal([]byte(`{"baseAmount":"348218523443","quotePrice":"4.0968426273752e-5","quoteTokenLiquidity":""}`), &swap); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	// 348218523443 × 0.000040968426273752 = 14265964.9048...
+	if got := swap.QuoteAmount(solanastreaming.RoundDown).String(); got != "14265964" {
+		t.Errorf("quote amount rounded down = %s", got)
+	}
+	if got := swap.QuoteAmount(solanastreaming.RoundUp).String(); got != "14265965" {
+		t.Errorf("quote amount rounded up = %s", got)
+	}
+	if swap.QuoteTokenLiquidity.IsSet() {
+		t.Error("empty quote liquidity is set")
+	}
+	if (solanastreaming.Swap{}).QuoteAmount(solanastreaming.RoundDown).IsSet() {
+		t.Error("quote amount without price is set")
+	}
+}
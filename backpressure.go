@@ -0,0 +1,201 @@
+package solanastreaming
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what happens to a notification when the subscription buffer is full.
+type OverflowPolicy int
+
+const (
+	OverflowBlock      OverflowPolicy = iota // keep everything, what does not fit the buffer is queued without bound for the consumer
+	OverflowDropOldest                       // drop the oldest buffered notification to make room
+	OverflowDropNewest                       // drop the notification that just arrived
+	OverflowDisconnect                       // end the subscription, Receive returns ErrSlowConsumer once the buffer is drained
+)
+
+const defaultSubscriptionBuffer = 1000
+
+// SubscriptionOption configures a single subscription, e.g. c.SubscribeSwaps(ctx, params, WithOverflowPolicy(OverflowDropOldest)).
+type SubscriptionOption func(*subscriptionConfig)
+
+type subscriptionConfig struct {
+	bufferSize int
+	overflow   OverflowPolicy
+	dropStale  bool
+}
+
+func newSubscriptionConfig(opts []SubscriptionOption) subscriptionConfig {
+	config := subscriptionConfig{
+		bufferSize: defaultSubscriptionBuffer,
+		overflow:   OverflowBlock,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// WithSubscriptionBuffer sets how many notifications are buffered for the consumer. Defaults to 1000, sizes below 1
+// are raised to 1 as the overflow policies need room for at least one notification.
+func WithSubscriptionBuffer(size int) SubscriptionOption {
+	return func(c *subscriptionConfig) {
+		c.bufferSize = max(size, 1)
+	}
+}
+
+// WithOverflowPolicy sets what happens when the buffer is full. Defaults to OverflowBlock.
+func WithOverflowPolicy(policy OverflowPolicy) SubscriptionOption {
+	return func(c *subscriptionConfig) {
+		c.overflow = policy
+	}
+}
+
+// activeSubscription tracks a live server subscription so it can be replayed after a reconnect.
+type activeSubscription struct {
+	id       uint // server side subscription id, changes after every reconnect
+	method   string
+	params   *json.RawMessage
+	messages chan *wireMessage
+	overflow OverflowPolicy
+	handle   Subscription // the handle returned to the caller, guarded by the client stateLock
+
+	notificationCounters
+	totals *notificationCounters // cumulative counters of the method on the client, nil for merged HotStandby subscriptions
+
+	generation atomic.Uint64 // params generation, incremented by the read loop when an UpdateParams response arrives
+	dropStale  bool
+	spillLock  sync.Mutex     // guards spill
+	spill      []*wireMessage // notifications that did not fit the buffer under OverflowBlock, read after the buffer
+
+	sendLock  sync.RWMutex  // held for reading while delivering, for writing while closing messages
+	done      chan struct{} // closed when the subscription ends, wakes blocked deliveries
+	closeOnce sync.Once
+	closed    bool  // guarded by sendLock
+	err       error // why the subscription ended if not unsubscribed, guarded by sendLock
+}
+
+func newActiveSubscription(method string, params *json.RawMessage, config subscriptionConfig) *activeSubscription {
+	return &activeSubscription{
+		notificationCounters: newNotificationCounters(),
+		method:               method,
+		params:               params,
+		messages:             make(chan *wireMessage, config.bufferSize),
+		overflow:             config.overflow,
+		dropStale:            config.dropStale,
+		done:                 make(chan struct{}),
+	}
+}
+
+// deliver hands a notification to a subscription according to its overflow policy.
+func (o *Client) deliver(a *activeSubscription, message *wireMessage) {
+	if a.offer(message) {
+		o.slowConsumer(a)
+	}
+}
+
+// offer buffers a notification according to the overflow policy. It reports whether the buffer overflowed under
+// OverflowDisconnect, the caller then has to end the subscription with slowConsumer.
+func (a *activeSubscription) offer(message *wireMessage) (overflowed bool) {
+	a.countReceived(message)
+	message.generation = a.generation.Load()
+	a.sendLock.RLock()
+	if a.closed {
+		a.sendLock.RUnlock()
+		message.release()
+		return false
+	}
+	switch a.overflow {
+	case OverflowBlock:
+		a.spillOver(message)
+	case OverflowDropNewest:
+		select {
+		case a.messages <- message:
+		default:
+			a.countDropped()
+			message.release()
+		}
+	case OverflowDropOldest:
+		for sent := false; !sent; {
+			select {
+			case a.messages <- message:
+				sent = true
+			default:
+				select {
+				case oldest := <-a.messages:
+					a.countDropped()
+					oldest.release()
+				default:
+				}
+			}
+		}
+	case OverflowDisconnect:
+		select {
+		case a.messages <- message:
+		default:
+			a.countDropped()
+			message.release()
+			overflowed = true
+		}
+	}
+	a.sendLock.RUnlock()
+	return overflowed
+}
+
+// slowConsumer ends a subscription that overflowed under OverflowDisconnect.
+func (o *Client) slowConsumer(a *activeSubscription) {
+	o.log.Error("wss slow consumer, subscription dropped", LogKeyMethod, a.method, LogKeySubscriptionID, a.id)
+	a.fail(ErrSlowConsumer)
+	o.dropSubscription(a)
+	// let the server know, nobody is waiting for the answer
+	go o.requestUnsubscribe(context.Background(), unsubscribeMethods[a.method], a)
+}
+
+// spillOver buffers a notification under OverflowBlock. The read loop serves every subscription and request, so it
+// never waits for a consumer: once the buffer is full notifications queue up in spill, which Receive drains in order
+// after the buffer. A stalled consumer only grows its own queue.
+func (a *activeSubscription) spillOver(message *wireMessage) {
+	a.spillLock.Lock()
+	defer a.spillLock.Unlock()
+	if len(a.spill) == 0 {
+		select {
+		case a.messages <- message:
+			return
+		default:
+		}
+	}
+	a.spill = append(a.spill, message)
+}
+
+// fail records why the subscription is ending, Receive returns it instead of ErrSubscriptionClosed.
+func (a *activeSubscription) fail(err error) {
+	a.sendLock.Lock()
+	defer a.sendLock.Unlock()
+	if a.err == nil {
+		a.err = err
+	}
+}
+
+// closeErr returns the error Receive reports once the subscription channel is closed.
+func (a *activeSubscription) closeErr() error {
+	a.sendLock.RLock()
+	defer a.sendLock.RUnlock()
+	if a.err != nil {
+		return a.err
+	}
+	return ErrSubscriptionClosed
+}
+
+// close closes the subscription channel, waiting for in flight deliveries to give up first.
+func (a *activeSubscription) close() {
+	a.closeOnce.Do(func() {
+		close(a.done)
+		a.sendLock.Lock()
+		a.closed = true
+		close(a.messages)
+		a.sendLock.Unlock()
+	})
+}
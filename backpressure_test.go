@@ -0,0 +1,205 @@
+package solanastreaming_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+func TestOverflowPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []solanastreaming.SubscriptionOption
+		pushed   uint64
+		received uint64   // reached the subscription before anything is read
+		want     []uint64 // slots read, in order
+		dropped  uint64
+		err      error // ends the subscription after want
+	}{{
+		name:     "block",
+		opts:     []solanastreaming.SubscriptionOption{solanastreaming.WithSubscriptionBuffer(2)},
+		pushed:   5,
+		received: 5, // queued behind the buffer
+		want:     []uint64{1, 2, 3, 4, 5},
+	}, {
+		name:     "drop oldest",
+		opts:     []solanastreaming.SubscriptionOption{solanastreaming.WithSubscriptionBuffer(2), solanastreaming.WithOverflowPolicy(solanastreaming.OverflowDropOldest)},
+		pushed:   5,
+		received: 5,
+		want:     []uint64{4, 5},
+		dropped:  3,
+	}, {
+		name:     "drop oldest without a buffer",
+		opts:     []solanastreaming.SubscriptionOption{solanastreaming.WithSubscriptionBuffer(0), solanastreaming.WithOverflowPolicy(solanastreaming.OverflowDropOldest)},
+		pushed:   3,
+		received: 3,
+		want:     []uint64{3},
+		dropped:  2,
+	}, {
+		name:     "drop newest",
+		opts:     []solanastreaming.SubscriptionOption{solanastreaming.WithSubscriptionBuffer(2), solanastreaming.WithOverflowPolicy(solanastreaming.OverflowDropNewest)},
+		pushed:   5,
+		received: 5,
+		want:     []uint64{1, 2},
+		dropped:  3,
+	}, {
+		name:     "disconnect",
+		opts:     []solanastreaming.SubscriptionOption{solanastreaming.WithSubscriptionBuffer(2), solanastreaming.WithOverflowPolicy(solanastreaming.OverflowDisconnect)},
+		pushed:   3,
+		received: 3,
+		want:     []uint64{1, 2},
+		dropped:  1,
+		err:      solanastreaming.ErrSlowConsumer,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := testContext(t)
+			srv := solanastreamingtest.NewServer()
+			defer srv.Close()
+			cli := newTestClient(t, srv)
+			sub, err := cli.SubscribeSwaps(ctx, nil, test.opts...)
+			if err != nil {
+				t.Fatalf("failed to subscribe: %v", err)
+			}
+
+			for slot := uint64(1); slot <= test.pushed; slot++ {
+				srv.PushSwap(solanastreaming.SwapNotification{Slot: slot})
+			}
+			// nothing is read until every notification reached the subscription
+			waitUntil(t, ctx, func() bool {
+				stats := sub.Stats()
+				return stats.Received == test.received && stats.Dropped == test.dropped
+			})
+
+			for _, slot := range test.want {
+				n, err := sub.Receive(ctx)
+				if err != nil || n.Slot != slot {
+					t.Fatalf("expected slot %d, got %+v, %v", slot, n, err)
+				}
+			}
+			if sub.Dropped() != test.dropped {
+				t.Fatalf("expected %d dropped, got %d", test.dropped, sub.Dropped())
+			}
+			if test.err != nil {
+				if _, err := sub.Receive(ctx); !errors.Is(err, test.err) {
+					t.Fatalf("expected %v, got %v", test.err, err)
+				}
+				if err := srv.WaitFor(ctx, func() bool { return srv.Subscriptions("swapSubscribe") == 0 }); err != nil {
+					t.Fatalf("server subscription not ended: %v", err)
+				}
+				return
+			}
+			short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+			if n, err := sub.Receive(short); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("unexpected notification: %+v, %v", n, err)
+			}
+		})
+	}
+}
+
+func TestOverflowPolicyEarlyNotifications(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy solanastreaming.OverflowPolicy
+		want   []uint64
+		err    error
+	}{
+		{name: "block", policy: solanastreaming.OverflowBlock, want: []uint64{1, 2, 3}},
+		{name: "drop newest", policy: solanastreaming.OverflowDropNewest, want: []uint64{1}},
+		{name: "drop oldest", policy: solanastreaming.OverflowDropOldest, want: []uint64{3}},
+		{name: "disconnect", policy: solanastreaming.OverflowDisconnect, want: []uint64{1}, err: solanastreaming.ErrSlowConsumer},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := testContext(t)
+			srv := solanastreamingtest.NewServer()
+			defer srv.Close()
+			cli := newTestClient(t, srv)
+
+			// the notifications arrive while the subscribe response is delayed, so they are held until it is read
+			srv.SetDelay(100 * time.Millisecond)
+			type result struct {
+				sub *solanastreaming.SwapsSubscription
+				err error
+			}
+			subscribed := make(chan result, 1)
+			go func() {
+				sub, err := cli.SubscribeSwaps(ctx, nil, solanastreaming.WithSubscriptionBuffer(1), solanastreaming.WithOverflowPolicy(test.policy))
+				subscribed <- result{sub, err}
+			}()
+			if err := srv.WaitFor(ctx, func() bool { return srv.Subscriptions("swapSubscribe") == 1 }); err != nil {
+				t.Fatal(err)
+			}
+			for slot := uint64(1); slot <= 3; slot++ {
+				srv.PushSwap(solanastreaming.SwapNotification{Slot: slot})
+			}
+			res := <-subscribed
+			if res.err != nil {
+				t.Fatalf("failed to subscribe: %v", res.err)
+			}
+
+			for _, slot := range test.want {
+				n, err := res.sub.Receive(ctx)
+				if err != nil || n.Slot != slot {
+					t.Fatalf("expected slot %d, got %+v, %v", slot, n, err)
+				}
+			}
+			if dropped := res.sub.Dropped(); dropped != uint64(3-len(test.want)) {
+				t.Fatalf("expected %d dropped, got %d", 3-len(test.want), dropped)
+			}
+			if test.err != nil {
+				if _, err := res.sub.Receive(ctx); !errors.Is(err, test.err) {
+					t.Fatalf("expected %v, got %v", test.err, err)
+				}
+			}
+			if cli.UnclaimedNotifications() != 0 {
+				t.Fatalf("%d notifications unclaimed", cli.UnclaimedNotifications())
+			}
+		})
+	}
+}
+
+func TestOverflowBlockStallsOnlyItsSubscription(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	// nobody reads the swaps
+	stalled, err := cli.SubscribeSwaps(ctx, nil, solanastreaming.WithSubscriptionBuffer(1))
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	blocks, err := cli.SubscribeLatestBlock(ctx)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	for slot := uint64(1); slot <= 3; slot++ {
+		srv.PushSwap(solanastreaming.SwapNotification{Slot: slot})
+	}
+	waitUntil(t, ctx, func() bool { return stalled.Stats().Received == 3 })
+
+	// other subscriptions and requests are still served
+	srv.PushLatestBlock(solanastreaming.LatestBlockNotification{Block: 7})
+	if n, err := blocks.Receive(ctx); err != nil || n.Block != 7 {
+		t.Fatalf("unexpected block: %+v, %v", n, err)
+	}
+	if _, err := cli.SubscribeNewPairs(ctx, nil); err != nil {
+		t.Fatalf("failed to subscribe while a consumer is stalled: %v", err)
+	}
+
+	// the stalled consumer still gets everything in order
+	for slot := uint64(1); slot <= 3; slot++ {
+		if n, err := stalled.Receive(ctx); err != nil || n.Slot != slot {
+			t.Fatalf("expected slot %d, got %+v, %v", slot, n, err)
+		}
+	}
+	if stalled.Dropped() != 0 {
+		t.Fatalf("dropped %d notifications", stalled.Dropped())
+	}
+}
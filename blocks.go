@@ -2,25 +2,25 @@ package solanastreaming
 
 import (
 	"context"
+	"encoding/json"
+	"iter"
 )
 
 type LatestBlockSubscription struct {
 	sub subscription[LatestBlockNotification]
 }
 
-func (c *Client) SubscribeLatestBlock(ctx context.Context) (*LatestBlockSubscription, error) {
-	subscriptionID, receiver, err := c.subscribe(ctx, "latestBlockSubscribe", nil)
+func (c *Client) SubscribeLatestBlock(ctx context.Context, opts ...SubscriptionOption) (*LatestBlockSubscription, error) {
+	active, err := c.subscribe(ctx, "latestBlockSubscribe", nil, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return &LatestBlockSubscription{
-		sub: subscription[LatestBlockNotification]{
-			ID:       subscriptionID,
-			messages: receiver,
-			client:   c,
-		},
-	}, nil
+	sub := &LatestBlockSubscription{
+		sub: newSubscription[LatestBlockNotification](c, active),
+	}
+	c.track(active, sub)
+	return sub, nil
 }
 
 func (s *LatestBlockSubscription) Receive(ctx context.Context) (LatestBlockNotification, error) {
@@ -30,7 +30,81 @@ func (s *LatestBlockSubscription) Receive(ctx context.Context) (LatestBlockNotif
 	return receive[LatestBlockNotification](ctx, s.sub)
 }
 
-// Unsubscribe from the latest block notifications. To prevent deadlocks, Avoid putting your Unsubscribe() call in your Receive() loop
+// ReceiveWithInfo is Receive that also reports the params generation and arrival time of the notification.
+func (s *LatestBlockSubscription) ReceiveWithInfo(ctx context.Context) (LatestBlockNotification, NotificationInfo, error) {
+	if s == nil {
+		return LatestBlockNotification{}, NotificationInfo{}, ErrNoSubscription
+	}
+	return receiveWithInfo[LatestBlockNotification](ctx, s.sub)
+}
+
+// All returns an iterator over the notifications, for use with range. It stops when ctx is done, after Unsubscribe
+// or after yielding the error that ended the connection.
+func (s *LatestBlockSubscription) All(ctx context.Context) iter.Seq2[LatestBlockNotification, error] {
+	if s == nil {
+		return func(yield func(LatestBlockNotification, error) bool) {
+			yield(LatestBlockNotification{}, ErrNoSubscription)
+		}
+	}
+	return all(ctx, s.sub)
+}
+
+// Chan returns a channel of notifications that is closed when the subscription ends. Use Errors or Err for errors.
+// Chan, Errors and OnLatestBlock share the same stream and should not be combined with Receive or All.
+func (s *LatestBlockSubscription) Chan() <-chan LatestBlockNotification {
+	s.sub.delivery.start(s.sub)
+	return s.sub.delivery.ch
+}
+
+// Errors returns a channel of errors that is closed together with Chan. Errors are dropped if nobody reads them.
+func (s *LatestBlockSubscription) Errors() <-chan error {
+	s.sub.delivery.start(s.sub)
+	return s.sub.delivery.errs
+}
+
+// Err returns the error that closed Chan, or nil if it was closed by Unsubscribe.
+func (s *LatestBlockSubscription) Err() error {
+	return s.sub.delivery.Err()
+}
+
+// OnLatestBlock calls handler for every latest block notification until the subscription ends.
+// Blocks are handled in order by a single worker unless WithUnordered is set.
+func (s *LatestBlockSubscription) OnLatestBlock(handler func(LatestBlockNotification), opts ...HandlerOption) {
+	s.sub.delivery.start(s.sub)
+	handle(s.sub.delivery, handler, func(LatestBlockNotification) uint64 { return 0 }, opts)
+}
+
+// ID returns the server side subscription id, see Subscription.
+func (s *LatestBlockSubscription) ID() uint {
+	return s.sub.ID()
+}
+
+// Method returns "latestBlockSubscribe".
+func (s *LatestBlockSubscription) Method() string {
+	return s.sub.Method()
+}
+
+// Params returns the current subscription params.
+func (s *LatestBlockSubscription) Params() json.RawMessage {
+	return s.sub.Params()
+}
+
+// Stats returns the subscription counters.
+func (s *LatestBlockSubscription) Stats() SubscriptionStats {
+	return s.sub.Stats()
+}
+
+// Done is closed when the subscription ends.
+func (s *LatestBlockSubscription) Done() <-chan struct{} {
+	return s.sub.Done()
+}
+
+// Dropped returns how many notifications were discarded by the overflow policy.
+func (s *LatestBlockSubscription) Dropped() uint64 {
+	return s.Stats().Dropped
+}
+
+// Unsubscribe from the latest block notifications. It is safe to call from any goroutine, including a Receive loop.
 func (s *LatestBlockSubscription) Unsubscribe(ctx context.Context) error {
 	return unsubscribe[LatestBlockNotification](ctx, s.sub, "latestBlockUnsubscribe")
 }
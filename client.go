@@ -7,124 +7,277 @@ package solanastreaming
 import (
 	"context"
 	"crypto/rand"
+	"crypto/tls"
 	"encoding/json"
-	"io"
+	"log/slog"
 	"math/big"
+	"net"
 	"net/http"
+	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 )
 
 type Client struct {
 	apiKey     string
-	host       string
-	log        *logrus.Logger
-	conn       *websocket.Conn
+	endpoints  []Endpoint
+	log        *levelLogger
+	conn       Conn
 	generalErr error
-	lock       sync.Mutex // for writing to the same connection
-	receivers  map[receiver]chan *wireMessage
+	lock       sync.Mutex                     // for writing to the same connection
+	receivers  map[int]*syncRequest           // sync requests waiting for a response by request id
+	streams    map[uint]*activeSubscription   // subscriptions by server side subscription id
+	pending    map[uint]*pendingNotifications // notifications for subscription ids not registered yet
+	unclaimed  atomic.Uint64
+	readDone   chan struct{} // closed when the read loop of conn exits
+	connID     atomic.Uint64 // id of the current connection, incremented by every connect and reconnect
+
+	reconnectPolicy ReconnectPolicy
+	stateLock       sync.Mutex                       // guards generalErr, closed, dead and active
+	closed          bool                             // set by Close so a dropped read does not trigger a reconnect
+	dead            chan struct{}                    // closed once the client gives up and generalErr is final
+	active          map[*activeSubscription]struct{} // subscriptions to replay after a reconnect
+	methods         map[string]*notificationCounters // cumulative notification counters by subscription method, guarded by stateLock
+	state           ConnectionState
+	hooks           eventHooks
+	keepalive       KeepaliveConfig
+	rtt             atomic.Int64 // last measured ping round trip in nanoseconds
+	reconnects      atomic.Uint64
+	requests        atomic.Uint64
+	requestTimeouts atomic.Uint64
+	requestLatency  *latencyHistogram
+
+	// connection options, see options.go
+	transport        Transport
+	dialer           *websocket.Dialer
+	tlsConfig        *tls.Config
+	proxy            func(*http.Request) (*url.URL, error)
+	headers          http.Header
+	handshakeTimeout time.Duration
+	requestTimeout   time.Duration
+	readBufferSize   int
+	writeBufferSize  int
+	userAgent        string
+	probeEndpoints   bool
+	instrumentation  Instrumentation
+	recorder         atomic.Pointer[Recorder]
+	endpointHealth   map[string]EndpointHealth // results of the last ProbeEndpoints, guarded by stateLock
+	currentEndpoint  string                    // guarded by stateLock
 }
 
-// New creates a new client instance.
-func New(apiKey string) *Client {
-	logger := logrus.New()
-	logger.SetLevel(logrus.PanicLevel) // default to panic level, can be changed later
-	return &Client{
-		apiKey:    apiKey,
-		host:      "wss://api.solanastreaming.com",
-		log:       logger,
-		receivers: make(map[receiver]chan *wireMessage),
+// New creates a new client instance. Options can be passed to change the defaults, e.g. New(apiKey, WithRequestTimeout(10*time.Second)).
+func New(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		apiKey:          apiKey,
+		endpoints:       []Endpoint{{URL: defaultHost}},
+		log:             newLevelLogger(), // logging is off until SetLogLevel or SetLogger is called
+		receivers:       make(map[int]*syncRequest),
+		streams:         make(map[uint]*activeSubscription),
+		pending:         make(map[uint]*pendingNotifications),
+		reconnectPolicy: DefaultReconnectPolicy(),
+		keepalive:       DefaultKeepaliveConfig(),
+		dead:            make(chan struct{}),
+		active:          make(map[*activeSubscription]struct{}),
+		methods:         make(map[string]*notificationCounters),
+		headers:         make(http.Header),
+		requestTimeout:  defaultRequestTimeout,
+		userAgent:       defaultUserAgent,
+		requestLatency:  newLatencyHistogram(requestBounds),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-func (o *Client) SetLogLevel(level logrus.Level) {
-	o.log.SetLevel(level)
+// SetLogLevel sets the lowest level the client logs at. Use LevelOff to turn logging off.
+func (o *Client) SetLogLevel(level slog.Level) {
+	o.log.level.Set(level)
 }
 func (o *Client) SetHost(host string) {
-	o.host = host
+	o.SetEndpoints(Endpoint{URL: host})
+}
+
+// SetLogger sets the logger used by the client, e.g. slog.Default(). Every record is passed to it until SetLogLevel is called.
+func (o *Client) SetLogger(logger Logger) {
+	o.log.set(logger)
 }
-func (o *Client) SetLogger(logger *logrus.Logger) {
-	o.log = logger
+
+// SetReconnectPolicy changes how the client reconnects after the connection drops. Use ReconnectPolicy{} to disable reconnects.
+func (o *Client) SetReconnectPolicy(policy ReconnectPolicy) {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	o.reconnectPolicy = policy
 }
 
+// Close drops the connection without reconnecting.
 func (o *Client) Close() error {
-	if o.conn != nil {
-		return o.conn.Close()
+	o.stateLock.Lock()
+	o.closed = true
+	o.stateLock.Unlock()
+	o.fail(ErrClientClosed)
+	o.setState(StateClosed, nil)
+
+	o.lock.Lock()
+	conn := o.conn
+	o.lock.Unlock()
+	// the read loop closes the connection itself when it fails
+	if conn != nil {
+		if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			return err
+		}
 	}
 	return nil
 }
 
 // Connect establishes a WebSocket connection to the Solana Streaming API and should always be called before any other methods.
-func (o *Client) Connect(ctx context.Context) error {
+// The context bounds the dial and handshake.
+func (o *Client) Connect(ctx context.Context) (err error) {
+	op := &Operation{Name: OperationConnect}
+	ctx, end := o.startOperation(ctx, op)
+	defer func() { end(err) }()
+
+	o.stateLock.Lock()
 	o.generalErr = nil
-	conn, resp, err := websocket.DefaultDialer.Dial(o.host, http.Header{
-		"X-API-KEY":  []string{o.apiKey},
-		"User-Agent": []string{"solanastreaming-client-go"},
-	})
+	o.closed = false
+	select {
+	case <-o.dead:
+		o.dead = make(chan struct{})
+	default:
+	}
+	probe := o.probeEndpoints && len(o.endpoints) > 1
+	o.stateLock.Unlock()
+
+	o.setState(StateConnecting, nil)
+	if probe {
+		o.ProbeEndpoints(ctx)
+	}
+	conn, err := o.dial(ctx, false)
 	if err != nil {
-		var reason []byte
-		if resp != nil {
-			reason, _ = io.ReadAll(resp.Body)
-			resp.Body.Close()
-			if resp.StatusCode == http.StatusTooManyRequests {
-				err = errors.Wrapf(ErrRateLimitExceeded, err.Error())
-			}
-		}
-		o.log.Errorf("wss dial: %s %s", err.Error(), string(reason))
-		return errors.Wrap(err, string(reason))
+		o.setState(StateDisconnected, err)
+		return err
 	}
+	op.Endpoint = o.Endpoint()
+	o.startReading(conn)
+	o.log.Info("wss connected", LogKeyConnID, o.connID.Load(), LogKeyEndpoint, op.Endpoint)
+	o.setState(StateConnected, nil)
+	return nil
+}
+
+// startReading makes conn the current connection and starts the read loop for it.
+func (o *Client) startReading(conn Conn) {
+	readDone := make(chan struct{})
+	connID := o.connID.Add(1)
+	o.lock.Lock()
 	o.conn = conn
+	o.readDone = readDone
+	o.dropPending()
+	o.lock.Unlock()
 
-	go o.receiveMessages()
-	return nil
+	go func() {
+		defer close(readDone)
+		o.receiveMessages(conn, connID)
+	}()
+}
+
+// dialHost opens a new connection to host.
+func (o *Client) dialHost(ctx context.Context, host string) (Conn, error) {
+	transport := o.transport
+	if transport == nil {
+		transport = &WebsocketTransport{Dialer: o.newDialer()}
+	}
+	conn, err := transport.Dial(ctx, host, o.handshakeHeader())
+	if err != nil {
+		o.log.Error("wss dial failed", LogKeyEndpoint, host, LogKeyError, err)
+		return nil, err
+	}
+	return conn, nil
 }
 
-func (o *Client) receiveMessages() {
+func (o *Client) receiveMessages(conn Conn, connID uint64) {
+	keepalive := o.startKeepalive(conn)
 	for {
-		_, message, err := o.conn.ReadMessage()
+		frame, err := readFrame(conn)
 		if err != nil {
 			// cant receive so reconnect (can be triggered by set read deadline)
-			o.log.Errorf("wss read: %s", err.Error())
-			// set generat eerr is not already set (to be returned to receivers or subscribe calls)
-			if o.generalErr == nil {
-				o.generalErr = err
-			}
+			o.log.Error("wss read failed", LogKeyConnID, connID, LogKeyError, err)
+			keepalive.stop()
+			conn.Close()
+			o.reconnect(err)
 			return
 		}
-		o.log.Debugf("WSS_RECEIVE: %s", string(message))
+		receivedAt := time.Now()
+		keepalive.alive()
+		o.record(FrameReceived, frame.data)
+		if o.log.debugEnabled() {
+			o.log.Debug("wss receive", LogKeyConnID, connID, "frame", string(frame.data))
+		}
 
-		var event wireMessage
-		err = json.Unmarshal(message, &event)
+		event, err := parseFrame(frame)
 		if err != nil {
-			o.log.Errorf("wss unmarshal: %s", err.Error())
+			o.log.Error("wss unmarshal failed", LogKeyConnID, connID, LogKeyError, err)
 			continue
 		}
+		event.receivedAt = receivedAt
+		event.size = len(frame.data)
 
-		// response to jsonID or subscription_id
+		o.dispatch(event)
+	}
+}
+
+// dispatch routes a message to the request or subscription it belongs to. It never ends the read loop.
+func (o *Client) dispatch(event *wireMessage) {
+	// response to a sync request
+	if event.ID != 0 {
+		o.lock.Lock()
+		request := o.receivers[event.ID]
+		o.lock.Unlock()
+		if request == nil {
+			o.log.Debug("wss response for unknown request", LogKeyRequestID, event.ID)
+			event.release()
+			return
+		}
+		if request.onResponse != nil {
+			request.onResponse(event)
+		}
+		select {
+		case request.response <- event:
+		default:
+			o.log.Debug("wss duplicate response", LogKeyRequestID, event.ID)
+			event.release()
+		}
+		return
+	}
+
+	// notification or error for a subscription, delivered outside the lock so a slow consumer only blocks its own subscription
+	if event.SubscriptionID != 0 {
 		o.lock.Lock()
-		for v := range o.receivers {
-			ch := o.receivers[v]
-			// this is an error message, send to firt receiver and exit
-			if event.ID == 0 && event.Error != nil && event.Error.Message != "" && ch != nil {
-				ch <- &event
-				o.lock.Unlock()
-				return
-			}
-			if v.Type == receiverTypeBySubscriptionID && event.SubscriptionID == uint(v.Value) {
-				ch <- &event
-				break
-			}
-			if v.Type == receiverTypeByRequestID && event.ID == v.Value {
-				ch <- &event
-				break
-			}
+		stream := o.streams[event.SubscriptionID]
+		if stream == nil {
+			// the subscribe response may not have been processed yet
+			o.hold(event)
 		}
 		o.lock.Unlock()
+		if stream != nil {
+			o.deliver(stream, event)
+		}
+		return
 	}
+
+	// error the server could not attribute to a request or subscription
+	if event.Error != nil {
+		err := newServerError(event)
+		o.log.Error("wss server error", LogKeyError, err)
+		o.emitError(err)
+		event.release()
+		return
+	}
+	o.log.Debug("wss unroutable message", LogKeyMethod, event.Method)
+	event.release()
 }
 
 // send a message over the wire without waiting for a response
@@ -136,54 +289,79 @@ func (o *Client) sendMessage(msg wireMessage) error {
 	}
 	d, err := json.Marshal(msg)
 	if err != nil {
-		o.log.Errorf("wss marshal: %s", err.Error())
+		o.log.Error("wss marshal failed", LogKeyMethod, msg.Method, LogKeyRequestID, msg.ID, LogKeyError, err)
 		return err
 	}
-	o.log.Debugf("WSS_SEND: %s", string(d))
-	err = o.conn.WriteMessage(websocket.TextMessage, d)
+	connID := o.connID.Load()
+	if o.log.debugEnabled() {
+		o.log.Debug("wss send", LogKeyConnID, connID, LogKeyMethod, msg.Method, LogKeyRequestID, msg.ID, "frame", string(d))
+	}
+	o.record(FrameSent, d)
+	err = o.conn.WriteFrame(d)
 	if err != nil {
-		o.log.Errorf("wss write: %s", err.Error())
+		o.log.Error("wss write failed", LogKeyConnID, connID, LogKeyMethod, msg.Method, LogKeyRequestID, msg.ID, LogKeyError, err)
 		return err
 	}
 	return nil
 }
 
+// syncRequest is a request waiting for its response.
+type syncRequest struct {
+	response   chan *wireMessage
+	onResponse func(*wireMessage) // called by the read loop before any later frame is dispatched, may be nil
+}
+
 // send a message over the wire and wait for a response
 func (o *Client) sendSyncMessage(ctx context.Context, msg wireMessage) (*wireMessage, error) {
+	return o.sendSyncMessageFunc(ctx, msg, nil)
+}
+
+// sendSyncMessageFunc is sendSyncMessage with a callback that runs on the read loop when the response arrives.
+func (o *Client) sendSyncMessageFunc(ctx context.Context, msg wireMessage, onResponse func(*wireMessage)) (response *wireMessage, err error) {
 	requestID := randRequestID()
 	msg.ID = requestID
+	ctx, end := o.startOperation(ctx, &Operation{Name: OperationRequest, Method: msg.Method, RequestID: requestID})
+	defer func() {
+		if err == nil && response.Error != nil && response.Error.Code != 0 {
+			end(newServerError(response))
+			return
+		}
+		end(err)
+	}()
 
-	// register response receiver
-	response := make(chan *wireMessage)
+	// register response receiver, buffered so the read loop never waits on a request that already timed out
+	responses := make(chan *wireMessage, 1)
 	o.lock.Lock()
-	receiverKey := receiver{
-		Type:  receiverTypeByRequestID,
-		Value: requestID,
-	}
-	o.receivers[receiverKey] = response
+	o.receivers[requestID] = &syncRequest{response: responses, onResponse: onResponse}
 	o.lock.Unlock()
 
 	// remove after to reduce chance of memory leak
 	defer func() {
 		o.lock.Lock()
-		delete(o.receivers, receiverKey)
+		delete(o.receivers, requestID)
 		o.lock.Unlock()
 	}()
 
-	err := o.sendMessage(msg)
+	sent := time.Now()
+	o.requests.Add(1)
+	err = o.sendMessage(msg)
 	if err != nil {
 		return nil, err
 	}
 
 	// ensure timeout if we havent received a response
-	timeout := time.After(5 * time.Second)
+	timeout := time.After(o.requestTimeout)
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
+	case <-o.deadChan():
+		return nil, o.err()
 	case <-timeout:
-		o.log.Errorf("wss timeout: %d", requestID)
-		return nil, errors.New("timeout")
-	case val := <-response:
+		o.requestTimeouts.Add(1)
+		o.log.Error("wss request timed out", LogKeyMethod, msg.Method, LogKeyRequestID, requestID)
+		return nil, ErrRequestTimeout
+	case val := <-responses:
+		o.requestLatency.observe(time.Since(sent))
 		return val, nil
 	}
 }
@@ -210,6 +388,31 @@ func (o *Client) sendSyncMessage(ctx context.Context, msg wireMessage) (*wireMes
 // 	close(outCh.(chan any))
 // }
 
+// err returns the error that ended the connection, if any.
+func (o *Client) err() error {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	return o.generalErr
+}
+
+// deadChan returns a channel that is closed once the client has given up on the connection.
+func (o *Client) deadChan() <-chan struct{} {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	return o.dead
+}
+
+// fail records err as the final connection error and wakes anything waiting on the connection.
+func (o *Client) fail(err error) {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	if o.generalErr != nil {
+		return
+	}
+	o.generalErr = err
+	close(o.dead)
+}
+
 func randRequestID() int {
 	requestID, _ := rand.Int(rand.Reader, big.NewInt(1000000))
 	return int(requestID.Int64()) + 1
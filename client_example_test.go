@@ -6,7 +6,7 @@ import (
 	"log"
 )
 
-func ExampleMain() {
+func Example() {
 	ctx := context.Background()
 	cli := New("ae2b1fca515949e5d54fb22b8ed95575")
 
@@ -1,31 +1,230 @@
-package solanastreaming
+package solanastreaming_test
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 
-	"github.com/sirupsen/logrus"
+	"github.com/gagliardetto/solana-go"
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
 )
 
+// newTestClient connects a client with fast reconnects to the mock server.
+func newTestClient(t *testing.T, srv *solanastreamingtest.Server, opts ...solanastreaming.Option) *solanastreaming.Client {
+	t.Helper()
+	policy := solanastreaming.DefaultReconnectPolicy()
+	policy.InitialBackoff = 10 * time.Millisecond
+	cli := solanastreaming.New("test-key", append([]solanastreaming.Option{
+		solanastreaming.WithHost(srv.URL()),
+		solanastreaming.WithReconnectPolicy(policy),
+	}, opts...)...)
+	if err := cli.Connect(context.Background()); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { cli.Close() })
+	return cli
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
 func TestClient(t *testing.T) {
-	ctx := context.Background()
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	sub, err := cli.SubscribeNewPairs(ctx, &solanastreaming.NewPairSubscribeParams{IncludeLaunchpadTokens: true})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	calls := srv.Calls("newPairSubscribe")
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 subscribe call, got %d", len(calls))
+	}
+	var params solanastreaming.NewPairSubscribeParams
+	if err := json.Unmarshal(calls[0].Params, &params); err != nil || !params.IncludeLaunchpadTokens {
+		t.Fatalf("unexpected subscribe params: %s", calls[0].Params)
+	}
+
+	mint := solana.NewWallet().PublicKey()
+	srv.PushNewPair(solanastreaming.NewPairNotification{
+		Slot: 10,
+		Pair: solanastreaming.Pair{BaseToken: solanastreaming.Token{Account: mint}},
+	})
+	ev, err := sub.Receive(ctx)
+	if err != nil {
+		t.Fatalf("receive error: %v", err)
+	}
+	if ev.Slot != 10 || ev.Pair.BaseToken.Account != mint {
+		t.Fatalf("unexpected notification: %#v", ev)
+	}
+}
+
+func TestClientReconnect(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	reconnected := make(chan solanastreaming.ReconnectEvent, 1)
+	cli.OnReconnect(func(ev solanastreaming.ReconnectEvent) { reconnected <- ev })
+
+	sub, err := cli.SubscribeSwaps(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	usd := 100.0
+	if err := sub.UpdateParams(ctx, &solanastreaming.SwapSubscribeParams{Include: solanastreaming.FilterFields{USDValue: &usd}}); err != nil {
+		t.Fatalf("failed to update params: %v", err)
+	}
+
+	srv.Disconnect()
+	select {
+	case <-reconnected:
+	case <-ctx.Done():
+		t.Fatal("client did not reconnect")
+	}
+	err = srv.WaitFor(ctx, func() bool { return srv.Subscriptions("swapSubscribe") == 1 })
+	if err != nil {
+		t.Fatal("subscription was not replayed")
+	}
+	params := srv.SubscriptionParams("swapSubscribe")
+	var replayed solanastreaming.SwapSubscribeParams
+	if err := json.Unmarshal(params[0], &replayed); err != nil || replayed.Include.USDValue == nil || *replayed.Include.USDValue != usd {
+		t.Fatalf("subscription replayed with stale params: %s", params[0])
+	}
+
+	srv.PushSwap(solanastreaming.SwapNotification{Slot: 42})
+	ev, err := sub.Receive(ctx)
+	if err != nil {
+		t.Fatalf("receive error after reconnect: %v", err)
+	}
+	if ev.Slot != 42 {
+		t.Fatalf("unexpected notification: %#v", ev)
+	}
+	if cli.State() != solanastreaming.StateConnected {
+		t.Fatalf("unexpected state: %s", cli.State())
+	}
+}
 
-	cli := New("1e41e2d28d4adec68accd1b120d3eec7")
-	cli.Connect(ctx)
-	cli.SetLogLevel(logrus.DebugLevel)
+func TestClientServerErrors(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
 
-	sub, err := cli.SubscribeNewPairs(ctx, &NewPairSubscribeParams{IncludeLaunchpadTokens: true})
+	unattributed := make(chan error, 1)
+	cli.OnError(func(err error) { unattributed <- err })
+
+	srv.FailNext("latestBlockSubscribe", 429, "too many subscriptions")
+	_, err := cli.SubscribeLatestBlock(ctx)
+	var serverErr *solanastreaming.ServerError
+	if !errors.As(err, &serverErr) || serverErr.Code != 429 {
+		t.Fatalf("expected server error, got %v", err)
+	}
+
+	sub, err := cli.SubscribeLatestBlock(ctx)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	srv.InjectError(-32000, "internal error")
+	select {
+	case err := <-unattributed:
+		if !errors.As(err, &serverErr) || serverErr.Message != "internal error" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("unattributed error was not reported")
+	}
+
+	srv.InjectSubscriptionError("latestBlockSubscribe", -32001, "lagging")
+	_, err = sub.Receive(ctx)
+	if !errors.As(err, &serverErr) || serverErr.Code != -32001 {
+		t.Fatalf("expected subscription error, got %v", err)
+	}
+
+	// the connection keeps streaming after errors
+	srv.PushLatestBlock(solanastreaming.LatestBlockNotification{Block: 7})
+	ev, err := sub.Receive(ctx)
+	if err != nil || ev.Block != 7 {
+		t.Fatalf("unexpected receive after errors: %#v %v", ev, err)
+	}
+}
+
+func TestClientShutdown(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	sub, err := cli.SubscribeLatestBlock(ctx)
 	if err != nil {
 		t.Fatalf("failed to subscribe: %v", err)
 	}
-	for {
+	for block := uint64(1); block <= 3; block++ {
+		srv.PushLatestBlock(solanastreaming.LatestBlockNotification{Block: block})
+	}
+	// the server writes in order so the notifications are buffered before the unsubscribe response arrives
+	done := make(chan error, 1)
+	go func() { done <- cli.Shutdown(ctx) }()
+
+	for block := uint64(1); block <= 3; block++ {
 		ev, err := sub.Receive(ctx)
-		if err != nil {
-			t.Logf("receive error: %v", err)
-			return
+		if err != nil || ev.Block != block {
+			t.Fatalf("expected block %d, got %#v %v", block, ev, err)
 		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("shutdown error: %v", err)
+	}
+	if _, err := sub.Receive(ctx); !errors.Is(err, solanastreaming.ErrSubscriptionClosed) {
+		t.Fatalf("expected ErrSubscriptionClosed, got %v", err)
+	}
+	if srv.Subscriptions("latestBlockSubscribe") != 0 {
+		t.Fatal("subscription was not unsubscribed")
+	}
+}
 
-		fmt.Printf("New Pair Received: %s", ev.Pair.BaseToken.Account)
+func TestClientSubscriptions(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	swaps, err := cli.SubscribeSwaps(ctx, &solanastreaming.SwapSubscribeParams{})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	if _, err := cli.SubscribeLatestBlock(ctx); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	subs := cli.Subscriptions()
+	if len(subs) != 2 || subs[0] != solanastreaming.Subscription(swaps) || subs[1].Method() != "latestBlockSubscribe" {
+		t.Fatalf("unexpected subscriptions: %v", subs)
+	}
+	if subs[0].Params() == nil || subs[1].Params() != nil {
+		t.Fatalf("unexpected params: %s %s", subs[0].Params(), subs[1].Params())
+	}
+
+	if err := cli.UnsubscribeAll(ctx); err != nil {
+		t.Fatalf("failed to unsubscribe: %v", err)
+	}
+	if len(cli.Subscriptions()) != 0 || srv.Subscriptions("swapSubscribe")+srv.Subscriptions("latestBlockSubscribe") != 0 {
+		t.Fatal("subscriptions still active")
+	}
+	select {
+	case <-swaps.Done():
+	default:
+		t.Fatal("Done not closed after unsubscribe")
 	}
 }
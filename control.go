@@ -0,0 +1,109 @@
+package solanastreaming
+
+import (
+	"context"
+	"time"
+)
+
+// NotificationInfo describes when a notification arrived and under which params it was delivered.
+type NotificationInfo struct {
+	Generation uint64    // params generation the notification was delivered under, incremented by every successful UpdateParams
+	Stale      bool      // delivered before the latest UpdateParams took effect
+	ReceivedAt time.Time // when the frame was read from the connection
+}
+
+// WithDropStale makes Receive skip notifications delivered under params replaced by UpdateParams. Skipped notifications are counted in Stats().Stale.
+func WithDropStale() SubscriptionOption {
+	return func(c *subscriptionConfig) {
+		c.dropStale = true
+	}
+}
+
+// take returns the next notification without waiting. ok is false if there is none, open is false once the subscription is closed and drained.
+func (a *activeSubscription) take() (message *wireMessage, open bool, ok bool) {
+	closed := false
+	select {
+	case v, open := <-a.messages:
+		if open {
+			return v, true, true
+		}
+		closed = true
+	default:
+	}
+
+	a.spillLock.Lock()
+	defer a.spillLock.Unlock()
+	if len(a.spill) > 0 {
+		message = a.spill[0]
+		a.spill[0] = nil
+		a.spill = a.spill[1:]
+		if len(a.spill) == 0 {
+			a.spill = nil
+		}
+		return message, true, true
+	}
+	return nil, false, closed
+}
+
+// buffered returns how many notifications are waiting to be read.
+func (a *activeSubscription) buffered() int {
+	a.spillLock.Lock()
+	defer a.spillLock.Unlock()
+	return len(a.messages) + len(a.spill)
+}
+
+// info describes a notification read from the subscription.
+func (a *activeSubscription) info(message *wireMessage) NotificationInfo {
+	return NotificationInfo{
+		Generation: message.generation,
+		Stale:      message.generation < a.generation.Load(),
+		ReceivedAt: message.receivedAt,
+	}
+}
+
+// next waits for the next notification. open is false once the subscription is closed and drained.
+func next[T any](ctx context.Context, sub subscription[T]) (*wireMessage, bool, error) {
+	// buffered notifications are still delivered after the connection has failed or is shutting down
+	if v, open, ok := sub.active.take(); ok {
+		return v, open, nil
+	}
+
+	var dead <-chan struct{}
+	if sub.client != nil {
+		if err := sub.client.err(); err != nil {
+			return nil, false, err
+		}
+		dead = sub.client.deadChan()
+	}
+	// notifications only spill while the buffer is full, so waiting on the buffer alone is enough
+	select {
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	case <-dead:
+		return nil, false, sub.client.err()
+	case v, open := <-sub.active.messages:
+		return v, open, nil
+	}
+}
+
+func receiveWithInfo[T any](ctx context.Context, sub subscription[T]) (T, NotificationInfo, error) {
+	for {
+		v, open, err := next(ctx, sub)
+		if err != nil {
+			var value T
+			return value, NotificationInfo{}, err
+		}
+		if !open {
+			value, err := decode[T](sub.active, v, open)
+			return value, NotificationInfo{}, err
+		}
+		info := sub.active.info(v)
+		if info.Stale && sub.active.dropStale {
+			sub.active.countStale()
+			v.release()
+			continue
+		}
+		value, err := decode[T](sub.active, v, open)
+		return value, info, err
+	}
+}
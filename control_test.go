@@ -0,0 +1,117 @@
+package solanastreaming_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+func TestSubscriptionControlInReceiveLoop(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	sub, err := cli.SubscribeSwaps(ctx, nil, solanastreaming.WithSubscriptionBuffer(1))
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	for slot := uint64(1); slot <= 5; slot++ {
+		srv.PushSwap(solanastreaming.SwapNotification{Slot: slot})
+	}
+	// the buffer is full and the read loop is blocked on the next notification
+	waitUntil(t, ctx, func() bool { return sub.Stats().Received >= 2 })
+
+	updated := false
+	for slot := uint64(1); slot <= 6; slot++ {
+		ev, info, err := sub.ReceiveWithInfo(ctx)
+		if err != nil {
+			t.Fatalf("failed to receive: %v", err)
+		}
+		if ev.Slot != slot {
+			t.Fatalf("notification out of order: got slot %d, want %d", ev.Slot, slot)
+		}
+		// slot 1 is read before the update, slots 2 to 5 were sent under the old params
+		if stale := slot > 1 && slot <= 5; info.Stale != stale || info.ReceivedAt.IsZero() {
+			t.Fatalf("unexpected info for slot %d: %+v", slot, info)
+		}
+		if !updated {
+			usd := 100.0
+			if err := sub.UpdateParams(ctx, &solanastreaming.SwapSubscribeParams{Include: solanastreaming.FilterFields{USDValue: &usd}}); err != nil {
+				t.Fatalf("failed to update params: %v", err)
+			}
+			updated = true
+			srv.PushSwap(solanastreaming.SwapNotification{Slot: 6})
+		}
+	}
+	if sub.Generation() != 1 {
+		t.Fatalf("unexpected generation: %d", sub.Generation())
+	}
+
+	for slot := uint64(7); slot <= 10; slot++ {
+		srv.PushSwap(solanastreaming.SwapNotification{Slot: slot})
+	}
+	waitUntil(t, ctx, func() bool { return sub.Stats().Received >= 8 })
+	if _, err := sub.Receive(ctx); err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+	if err := sub.Unsubscribe(ctx); err != nil {
+		t.Fatalf("failed to unsubscribe: %v", err)
+	}
+	for {
+		if _, err := sub.Receive(ctx); err != nil {
+			if !errors.Is(err, solanastreaming.ErrSubscriptionClosed) {
+				t.Fatalf("unexpected error after unsubscribe: %v", err)
+			}
+			break
+		}
+	}
+}
+
+func TestSubscriptionDropStale(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	sub, err := cli.SubscribeNewPairs(ctx, nil, solanastreaming.WithDropStale())
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	srv.PushNewPair(solanastreaming.NewPairNotification{Slot: 1})
+	srv.PushNewPair(solanastreaming.NewPairNotification{Slot: 2})
+	waitUntil(t, ctx, func() bool { return sub.Stats().Received == 2 })
+	if err := sub.UpdateParams(ctx, &solanastreaming.NewPairSubscribeParams{}); err != nil {
+		t.Fatalf("failed to update params: %v", err)
+	}
+	srv.PushNewPair(solanastreaming.NewPairNotification{Slot: 3})
+
+	ev, err := sub.Receive(ctx)
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+	if ev.Slot != 3 {
+		t.Fatalf("stale notification delivered: %#v", ev)
+	}
+	if stats := sub.Stats(); stats.Stale != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+// waitUntil polls cond until it holds, for client side state the mock server can not signal.
+func waitUntil(t *testing.T, ctx context.Context, cond func() bool) {
+	t.Helper()
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	for !cond() {
+		select {
+		case <-ctx.Done():
+			t.Fatal("condition not met in time")
+		case <-ticker.C:
+		}
+	}
+}
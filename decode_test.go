@@ -0,0 +1,293 @@
+package solanastreaming
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+const (
+	testSignature = "5VERv8NMvzbJMEkV8xnrLkEaWRtSz9CosKDYjCJjBRnbJLgp8uirBgmQpjKhoR4tjF3ZpRzrFmBV6UjKdiSZkQUW"
+	testWSOL      = "So11111111111111111111111111111111111111112"
+	testMint      = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+)
+
+var (
+	testSwap = `{"slot":339281734,"signature":"` + testSignature + `","blockTime":1760702400,"swap":{` +
+		`"sourceExchange":"pumpswap","ammAccount":"` + testMint + `","baseTokenMint":"` + testMint + `",` +
+		`"quoteTokenMint":"` + testWSOL + `","walletAccount":"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA",` +
+		`"quotePrice":"0.000000040968","usdValue":12.75,"baseAmount":"1500000000","swapType":"buy",` +
+		`"quoteTokenLiquidity":"84123456789"}}`
+	testPair = `{"slot":339281734,"signature":"` + testSignature + `","blockTime":1760702400,"pair":{` +
+		`"sourceExchange":"raydium","ammAccount":"` + testMint + `","baseToken":{"account":"` + testMint + `",` +
+		`"info":{"decimals":6,"supply":"1000000000000000","metadata":{"name":"Token \"Two\"","symbol":"TéST",` +
+		`"logo":"https://example.com/logo.png","socials":{"website":"https://example.com","x":null}},` +
+		`"mintAuthority":null,"freezeAuthority":"11111111111111111111111111111111"}},` +
+		`"quoteToken":{"account":"` + testWSOL + `","info":null},"baseTokenLiquidityAdded":"206900000000000",` +
+		`"quoteTokenLiquidityAdded":85005359983,"migration":"pumpfun"}}`
+	testFrame = `{"id":0,"subscription_id":42,"method":"swapNotification","params":` + testSwap + `}`
+)
+
+func TestDecodeNotification(t *testing.T) {
+	swaps := []string{
+		testSwap,
+		`{}`,
+		`null`,
+		` { "slot" : 1 , "swap" : null } `,
+		`{"slot":1,"unknown":{"nested":[1,2.5e3,{"a":[]},"x",true,false,null]},"swap":{"usdValue":null,"extra":[]}}`,
+		`{"swap":{"sourceExchange":"Raydium","swapType":"SELL","quotePrice":4.0968e-8,"baseAmount":123456789012345678901234}}`,
+		`{"swap":{"sourceExchange":"new_dex","swapType":"","quotePrice":"","baseAmount":null,"quoteTokenLiquidity":"-0.50"}}`,
+		`{"swap":{"quotePrice":"1.","baseAmount":"+7","quoteTokenLiquidity":"0"}}`,
+		`{"swap":{"ammAccount":"11111111111111111111111111111111","sourceExchange":"pumpfun"}}`,
+		`{"blockTime":18446744073709551615}`,
+		// invalid, both decoders have to fail
+		`{"slot":"1"}`,
+		`{"slot":1.5}`,
+		`{"slot":-1}`,
+		`{"slot":18446744073709551616}`,
+		`{"slot":01}`,
+		`{"signature":"not-base58"}`,
+		`{"signature":null}`,
+		`{"signature":"` + testWSOL + `"}`,
+		`{"swap":{"ammAccount":null}}`,
+		`{"swap":{"ammAccount":1}}`,
+		`{"swap":{"ammAccount":"` + testSignature + `"}}`,
+		`{"swap":{"ammAccount":"1111111111111111111111111111111111"}}`,
+		`{"swap":{"usdValue":"1"}}`,
+		`{"swap":{"quotePrice":true}}`,
+		`{"swap":{"quotePrice":"1.2.3"}}`,
+		`{"swap":{"swapType":1}}`,
+		`{"swap":[]}`,
+		`{"slot":1`,
+		`{"slot":1,}`,
+		`{"slot" 1}`,
+		`{"slot":1} {}`,
+		`{"unknown":[1,]}`,
+		`{"unknown":"tab	in string"}`,
+		`{"unknown":tru}`,
+		``,
+	}
+	for _, payload := range swaps {
+		checkDecode[SwapNotification](t, payload)
+	}
+	pairs := []string{
+		testPair,
+		`{"pair":{"baseToken":{"info":{"metadata":null}},"quoteToken":null}}`,
+		`{"pair":{"baseToken":{"info":{"metadata":{"socials":null},"supply":null}}}}`,
+		`{"pair":{"baseToken":{"info":{"decimals":-1}}}}`,
+		`{"pair":{"baseToken":{"info":{"mintAuthority":"abc"}}}}`,
+		`{"pair":{"baseToken":{"info":{"metadata":{"name":1}}}}}`,
+		`{"pair":{"baseToken":{"info":[]}}}`,
+	}
+	for _, payload := range pairs {
+		checkDecode[NewPairNotification](t, payload)
+	}
+	blocks := []string{
+		`{"block":339281734,"blockTime":1760702400}`,
+		`{"block":null}`,
+		`{"block":true}`,
+	}
+	for _, payload := range blocks {
+		checkDecode[LatestBlockNotification](t, payload)
+	}
+}
+
+// checkDecode compares the hand written decoder of T with encoding/json.
+func checkDecode[T any](t *testing.T, payload string) {
+	t.Helper()
+	var want, got T
+	wantErr := json.Unmarshal([]byte(payload), &want)
+	gotErr := decodeNotification([]byte(payload), &got)
+	if (wantErr == nil) != (gotErr == nil) {
+		t.Fatalf("%s: encoding/json error %v, decoder error %v", payload, wantErr, gotErr)
+	}
+	if wantErr != nil {
+		return
+	}
+	wantJSON, _ := json.Marshal(want)
+	gotJSON, _ := json.Marshal(got)
+	if !bytes.Equal(wantJSON, gotJSON) {
+		t.Fatalf("%s: decoded\n%s\nencoding/json decoded\n%s", payload, gotJSON, wantJSON)
+	}
+}
+
+func TestDecodeNotificationCopies(t *testing.T) {
+	frame := []byte(testPair)
+	var n NewPairNotification
+	if err := decodeNotification(frame, &n); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(n)
+	for i := range frame {
+		frame[i] = 'x'
+	}
+	if got, _ := json.Marshal(n); !bytes.Equal(got, want) {
+		t.Fatalf("decoded notification points into the frame:\n%s", got)
+	}
+}
+
+func TestParseFrame(t *testing.T) {
+	frames := []string{
+		testFrame,
+		`{"id":7,"result":{"subscription_id":42},"jsonrpc":"2.0"}`,
+		`{"id":7,"result":null}`,
+		`{"id":-1,"error":{"code":-32602,"message":"invalid \"params\""}}`,
+		`{"subscription_id":42,"method":"newMethod","params":null,"error":null}`,
+		`{"method":"swapNotification","params":[1,2]}`,
+		`{}`,
+		// invalid
+		`{"id":"7"}`,
+		`{"subscription_id":-1}`,
+		`{"error":{"code":"x"}}`,
+		`{"params":{"slot":}}`,
+		`{"id":1}}`,
+		`[]`,
+	}
+	for _, frame := range frames {
+		var want wireMessage
+		wantErr := json.Unmarshal([]byte(frame), &want)
+		got, gotErr := parseFrame(&frameBuffer{data: []byte(frame)})
+		if (wantErr == nil) != (gotErr == nil) {
+			t.Fatalf("%s: encoding/json error %v, parse error %v", frame, wantErr, gotErr)
+		}
+		if wantErr != nil {
+			continue
+		}
+		wantJSON, _ := json.Marshal(want)
+		gotJSON, _ := json.Marshal(got)
+		if !bytes.Equal(wantJSON, gotJSON) {
+			t.Fatalf("%s: parsed\n%s\nencoding/json parsed\n%s", frame, gotJSON, wantJSON)
+		}
+		got.release()
+	}
+}
+
+func TestDecodeBase58(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		var key solana.PublicKey
+		rng.Read(key[rng.Intn(4):])
+		var got solana.PublicKey
+		if !decodeBase58(got[:], []byte(key.String())) || got != key {
+			t.Fatalf("%s decoded to %s", key, got)
+		}
+		var signature solana.Signature
+		rng.Read(signature[rng.Intn(4):])
+		var gotSignature solana.Signature
+		if !decodeBase58(gotSignature[:], []byte(signature.String())) || gotSignature != signature {
+			t.Fatalf("%s decoded to %s", signature, gotSignature)
+		}
+	}
+	// random strings have to be accepted exactly when the solana package accepts them
+	for i := 0; i < 20000; i++ {
+		var s strings.Builder
+		s.WriteString(strings.Repeat("1", rng.Intn(3)))
+		for n := 40 + rng.Intn(8); n > 0; n-- {
+			s.WriteByte(base58Alphabet[rng.Intn(len(base58Alphabet))])
+		}
+		want, err := solana.PublicKeyFromBase58(s.String())
+		var got solana.PublicKey
+		if ok := decodeBase58(got[:], []byte(s.String())); ok != (err == nil) || (ok && got != want) {
+			t.Fatalf("%s: decoded %v %s, solana decoded %v %s", s.String(), ok, got, err, want)
+		}
+	}
+}
+
+func BenchmarkDecodeSwap(b *testing.B) {
+	payload := []byte(testSwap)
+	b.Run("encoding_json", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(payload)))
+		for i := 0; i < b.N; i++ {
+			var n SwapNotification
+			if err := json.Unmarshal(payload, &n); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("reader", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(payload)))
+		for i := 0; i < b.N; i++ {
+			var n SwapNotification
+			if err := decodeNotification(payload, &n); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkDecodeNewPair(b *testing.B) {
+	payload := []byte(testPair)
+	b.Run("encoding_json", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(payload)))
+		for i := 0; i < b.N; i++ {
+			var n NewPairNotification
+			if err := json.Unmarshal(payload, &n); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("reader", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(payload)))
+		for i := 0; i < b.N; i++ {
+			var n NewPairNotification
+			if err := decodeNotification(payload, &n); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkReceiveSwap measures a swap frame from the connection to the value returned by Receive, the way the
+// read loop used to handle it and the way it does now.
+func BenchmarkReceiveSwap(b *testing.B) {
+	frame := []byte(testFrame)
+	b.Run("encoding_json", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(frame)))
+		for i := 0; i < b.N; i++ {
+			data := append([]byte(nil), frame...)
+			var event wireMessage
+			if err := json.Unmarshal(data, &event); err != nil {
+				b.Fatal(err)
+			}
+			var n SwapNotification
+			if err := json.Unmarshal(*event.Params, &n); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		o := New("")
+		a := newActiveSubscription("swapSubscribe", nil, newSubscriptionConfig(nil))
+		a.id = 42
+		o.streams[a.id] = a
+		sub := newSubscription[SwapNotification](o, a)
+		ctx := context.Background()
+		b.ReportAllocs()
+		b.SetBytes(int64(len(frame)))
+		for i := 0; i < b.N; i++ {
+			buf := framePool.Get().(*frameBuffer)
+			buf.data = append(buf.data[:0], frame...)
+			event, err := parseFrame(buf)
+			if err != nil {
+				b.Fatal(err)
+			}
+			event.receivedAt = time.Now()
+			event.size = len(buf.data)
+			o.dispatch(event)
+			if _, err := receive(ctx, sub); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,459 @@
+package solanastreaming
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// decodeNotification decodes the params of a notification into value. The notification types have hand written
+// decoders, anything else goes through encoding/json. Decoded values never point into data.
+func decodeNotification[T any](data []byte, value *T) error {
+	r := jsonReader{data: data}
+	var err error
+	switch n := any(value).(type) {
+	case *SwapNotification:
+		err = n.readJSON(&r)
+	case *NewPairNotification:
+		err = n.readJSON(&r)
+	case *LatestBlockNotification:
+		err = n.readJSON(&r)
+	default:
+		return json.Unmarshal(data, value)
+	}
+	if err != nil {
+		return err
+	}
+	return r.end()
+}
+
+// fieldError names the member that failed to decode.
+func fieldError(key []byte, err error) error {
+	return fmt.Errorf("%s: %w", key, err)
+}
+
+func (n *SwapNotification) readJSON(r *jsonReader) error {
+	if r.null() {
+		return nil
+	}
+	for i := 0; ; i++ {
+		key, ok, err := r.member(i)
+		if err != nil || !ok {
+			return err
+		}
+		switch string(key) {
+		case "slot":
+			n.Slot, err = r.uint64()
+		case "signature":
+			err = r.signature(&n.Signature)
+		case "blockTime":
+			n.BlockTime, err = r.uint64()
+		case "swap":
+			err = n.Swap.readJSON(r)
+		default:
+			err = r.skip()
+		}
+		if err != nil {
+			return fieldError(key, err)
+		}
+	}
+}
+
+func (s *Swap) readJSON(r *jsonReader) error {
+	if r.null() {
+		return nil
+	}
+	for i := 0; ; i++ {
+		key, ok, err := r.member(i)
+		if err != nil || !ok {
+			return err
+		}
+		switch string(key) {
+		case "sourceExchange":
+			s.SourceExchange, err = r.exchange()
+		case "ammAccount":
+			err = r.publicKey(&s.AmmAccount)
+		case "baseTokenMint":
+			err = r.publicKey(&s.BaseTokenMint)
+		case "quoteTokenMint":
+			err = r.publicKey(&s.QuoteTokenMint)
+		case "walletAccount":
+			err = r.publicKey(&s.WalletAccount)
+		case "quotePrice":
+			s.QuotePrice, err = r.decimal()
+		case "usdValue":
+			s.USDValue, err = r.float64()
+		case "baseAmount":
+			s.BaseAmount, err = r.amount()
+		case "swapType":
+			s.SwapType, err = r.swapType()
+		case "quoteTokenLiquidity":
+			s.QuoteTokenLiquidity, err = r.amount()
+		default:
+			err = r.skip()
+		}
+		if err != nil {
+			return fieldError(key, err)
+		}
+	}
+}
+
+func (n *NewPairNotification) readJSON(r *jsonReader) error {
+	if r.null() {
+		return nil
+	}
+	for i := 0; ; i++ {
+		key, ok, err := r.member(i)
+		if err != nil || !ok {
+			return err
+		}
+		switch string(key) {
+		case "slot":
+			n.Slot, err = r.uint64()
+		case "signature":
+			err = r.signature(&n.Signature)
+		case "blockTime":
+			n.BlockTime, err = r.uint64()
+		case "pair":
+			err = n.Pair.readJSON(r)
+		default:
+			err = r.skip()
+		}
+		if err != nil {
+			return fieldError(key, err)
+		}
+	}
+}
+
+func (p *Pair) readJSON(r *jsonReader) error {
+	if r.null() {
+		return nil
+	}
+	for i := 0; ; i++ {
+		key, ok, err := r.member(i)
+		if err != nil || !ok {
+			return err
+		}
+		switch string(key) {
+		case "sourceExchange":
+			p.SourceExchange, err = r.exchange()
+		case "ammAccount":
+			err = r.publicKey(&p.AmmAccount)
+		case "baseToken":
+			err = p.BaseToken.readJSON(r)
+		case "quoteToken":
+			err = p.QuoteToken.readJSON(r)
+		case "baseTokenLiquidityAdded":
+			p.BaseTokenLiquidityAdded, err = r.amount()
+		case "quoteTokenLiquidityAdded":
+			p.QuoteTokenLiquidityAdded, err = r.amount()
+		case "migration":
+			p.Migration, err = r.exchange()
+		default:
+			err = r.skip()
+		}
+		if err != nil {
+			return fieldError(key, err)
+		}
+	}
+}
+
+func (t *Token) readJSON(r *jsonReader) error {
+	if r.null() {
+		return nil
+	}
+	for i := 0; ; i++ {
+		key, ok, err := r.member(i)
+		if err != nil || !ok {
+			return err
+		}
+		switch string(key) {
+		case "account":
+			err = r.publicKey(&t.Account)
+		case "info":
+			t.Info = nil
+			if !r.null() {
+				t.Info = new(TokenInfo)
+				err = t.Info.readJSON(r)
+			}
+		default:
+			err = r.skip()
+		}
+		if err != nil {
+			return fieldError(key, err)
+		}
+	}
+}
+
+func (t *TokenInfo) readJSON(r *jsonReader) error {
+	for i := 0; ; i++ {
+		key, ok, err := r.member(i)
+		if err != nil || !ok {
+			return err
+		}
+		switch string(key) {
+		case "decimals":
+			t.Decimals, err = r.uint()
+		case "supply":
+			t.Supply, err = r.amount()
+		case "metadata":
+			t.MetaData = nil
+			if !r.null() {
+				t.MetaData = new(TokenMetaData)
+				err = t.MetaData.readJSON(r)
+			}
+		case "mintAuthority":
+			t.MintAuthority, err = r.optionalPublicKey()
+		case "freezeAuthority":
+			t.FreezeAuthority, err = r.optionalPublicKey()
+		default:
+			err = r.skip()
+		}
+		if err != nil {
+			return fieldError(key, err)
+		}
+	}
+}
+
+func (m *TokenMetaData) readJSON(r *jsonReader) error {
+	for i := 0; ; i++ {
+		key, ok, err := r.member(i)
+		if err != nil || !ok {
+			return err
+		}
+		switch string(key) {
+		case "name":
+			m.Name, err = r.string()
+		case "symbol":
+			m.Symbol, err = r.string()
+		case "logo":
+			m.Logo, err = r.string()
+		case "socials":
+			m.Socials = nil
+			if !r.null() {
+				m.Socials = new(TokenSocials)
+				err = m.Socials.readJSON(r)
+			}
+		default:
+			err = r.skip()
+		}
+		if err != nil {
+			return fieldError(key, err)
+		}
+	}
+}
+
+func (s *TokenSocials) readJSON(r *jsonReader) error {
+	for i := 0; ; i++ {
+		key, ok, err := r.member(i)
+		if err != nil || !ok {
+			return err
+		}
+		switch string(key) {
+		case "website":
+			s.Website, err = r.string()
+		case "x":
+			s.X, err = r.string()
+		case "telegram":
+			s.Telegram, err = r.string()
+		default:
+			err = r.skip()
+		}
+		if err != nil {
+			return fieldError(key, err)
+		}
+	}
+}
+
+func (n *LatestBlockNotification) readJSON(r *jsonReader) error {
+	if r.null() {
+		return nil
+	}
+	for i := 0; ; i++ {
+		key, ok, err := r.member(i)
+		if err != nil || !ok {
+			return err
+		}
+		switch string(key) {
+		case "block":
+			n.Block, err = r.uint64()
+		case "blockTime":
+			n.BlockTime, err = r.uint64()
+		default:
+			err = r.skip()
+		}
+		if err != nil {
+			return fieldError(key, err)
+		}
+	}
+}
+
+// publicKey reads a base58 public key. Like PublicKey.UnmarshalJSON it fails on null.
+func (r *jsonReader) publicKey(key *solana.PublicKey) error {
+	text, err := r.text()
+	if err != nil || decodeBase58(key[:], text) {
+		return err
+	}
+	*key, err = solana.PublicKeyFromBase58(string(text))
+	return err
+}
+
+// optionalPublicKey reads a base58 public key, null reads as nil.
+func (r *jsonReader) optionalPublicKey() (*solana.PublicKey, error) {
+	if r.null() {
+		return nil, nil
+	}
+	key := new(solana.PublicKey)
+	return key, r.publicKey(key)
+}
+
+// signature reads a base58 transaction signature. Like Signature.UnmarshalJSON it fails on null.
+func (r *jsonReader) signature(signature *solana.Signature) error {
+	text, err := r.text()
+	if err != nil || decodeBase58(signature[:], text) {
+		return err
+	}
+	*signature, err = solana.SignatureFromBase58(string(text))
+	return err
+}
+
+// decimal reads a number or a string holding one. null and "" read as unset, like Decimal.UnmarshalJSON.
+func (r *jsonReader) decimal() (Decimal, error) {
+	var text []byte
+	var err error
+	if c := r.peek(); c == '"' || c == 'n' {
+		text, err = r.text()
+	} else {
+		text, err = r.number()
+	}
+	if err != nil || len(text) == 0 {
+		return Decimal{}, err
+	}
+	return parseDecimalText(text)
+}
+
+// amount reads an amount like Amount.UnmarshalJSON.
+func (r *jsonReader) amount() (Amount, error) {
+	d, err := r.decimal()
+	return Amount{value: d}, err
+}
+
+// exchange reads an exchange like Exchange.UnmarshalJSON.
+func (r *jsonReader) exchange() (Exchange, error) {
+	text, err := r.text()
+	if err != nil {
+		return "", err
+	}
+	for _, e := range knownExchanges {
+		if string(text) == string(e) {
+			return e, nil
+		}
+	}
+	return ParseExchange(string(text)), nil
+}
+
+// swapType reads a swap type like SwapType.UnmarshalJSON.
+func (r *jsonReader) swapType() (SwapType, error) {
+	text, err := r.text()
+	switch {
+	case err != nil:
+		return "", err
+	case string(text) == string(SwapTypeBuy):
+		return SwapTypeBuy, nil
+	case string(text) == string(SwapTypeSell):
+		return SwapTypeSell, nil
+	}
+	return ParseSwapType(string(text)), nil
+}
+
+// knownExchanges lists the exchanges so the ones sent as is can be decoded without allocating.
+var knownExchanges = func() []Exchange {
+	known := make([]Exchange, 0, len(exchanges))
+	for e := range exchanges {
+		known = append(known, e)
+	}
+	return known
+}()
+
+// parseDecimalText is ParseDecimal for plain numbers that fit a uint64, which is what the server sends. Anything
+// else is passed on to ParseDecimal.
+func parseDecimalText(text []byte) (Decimal, error) {
+	var unscaled uint64
+	digits, scale := 0, -1
+	negative, i := text[0] == '-', 0
+	if negative {
+		i++
+	}
+	for ; i < len(text); i++ {
+		switch c := text[i]; {
+		case c >= '0' && c <= '9':
+			unscaled = unscaled*10 + uint64(c-'0')
+			digits++
+			if scale >= 0 {
+				scale++
+			}
+		case c == '.' && scale < 0 && digits > 0:
+			scale = 0
+		default:
+			return ParseDecimal(string(text))
+		}
+	}
+	if digits == 0 || digits > 18 || scale == 0 {
+		return ParseDecimal(string(text))
+	}
+	value := new(big.Int).SetUint64(unscaled)
+	if negative {
+		value.Neg(value)
+	}
+	return Decimal{unscaled: value, scale: int32(max(scale, 0))}, nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Digits maps the characters of base58Alphabet to their value, anything else to -1.
+var base58Digits = func() (digits [256]int8) {
+	for i := range digits {
+		digits[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		digits[base58Alphabet[i]] = int8(i)
+	}
+	return digits
+}()
+
+// decodeBase58 decodes src into dst, a public key or signature, without allocating. It reports false unless src
+// decodes to exactly len(dst) bytes, callers then fall back to the solana package for its error.
+func decodeBase58(dst []byte, src []byte) bool {
+	var limbs [16]uint32
+	words := limbs[:len(dst)/4] // big endian
+	zeros := 0
+	for zeros < len(src) && src[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	for _, c := range src[zeros:] {
+		digit := base58Digits[c]
+		if digit < 0 {
+			return false
+		}
+		carry := uint64(digit)
+		for j := len(words) - 1; j >= 0; j-- {
+			t := uint64(words[j])*58 + carry
+			words[j], carry = uint32(t), t>>32
+		}
+		if carry != 0 {
+			return false
+		}
+	}
+	for j, word := range words {
+		binary.BigEndian.PutUint32(dst[4*j:], word)
+	}
+	// every leading 1 stands for exactly one leading zero byte
+	leading := 0
+	for leading < len(dst) && dst[leading] == 0 {
+		leading++
+	}
+	return leading == zeros
+}
@@ -0,0 +1,166 @@
+package solanastreaming
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// delivery pumps a subscription into a channel for Chan and the handler registration methods.
+type delivery[T any] struct {
+	once sync.Once
+	ch   chan T
+	errs chan error
+
+	lock sync.Mutex
+	err  error // the error that ended the subscription
+}
+
+func newDelivery[T any]() *delivery[T] {
+	return &delivery[T]{
+		ch:   make(chan T),
+		errs: make(chan error, 16),
+	}
+}
+
+// start begins pumping notifications, only the first call has an effect.
+func (d *delivery[T]) start(sub subscription[T]) {
+	d.once.Do(func() {
+		go d.pump(sub)
+	})
+}
+
+func (d *delivery[T]) pump(sub subscription[T]) {
+	defer close(d.errs)
+	defer close(d.ch)
+	for value, err := range all(context.Background(), sub) {
+		if err != nil {
+			if sub.ended() {
+				d.lock.Lock()
+				d.err = err
+				d.lock.Unlock()
+			}
+			select {
+			case d.errs <- err:
+			default: // nobody is reading errors
+			}
+			continue
+		}
+		select {
+		case d.ch <- value:
+		case <-sub.active.done:
+			return
+		}
+	}
+}
+
+func (d *delivery[T]) Err() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.err
+}
+
+// HandlerOption configures handler delivery, e.g. sub.OnSwap(handle, WithWorkers(8)).
+type HandlerOption func(*handlerConfig)
+
+type handlerConfig struct {
+	workers   int
+	unordered bool
+	onError   func(error)
+}
+
+// WithWorkers sets how many goroutines call the handler concurrently. Defaults to 1.
+func WithWorkers(workers int) HandlerOption {
+	return func(c *handlerConfig) {
+		c.workers = max(workers, 1)
+	}
+}
+
+// WithUnordered lets any free worker handle the next notification. By default notifications with the same key,
+// such as the amm account of a swap, are always handled by the same worker so they are handled in order.
+func WithUnordered() HandlerOption {
+	return func(c *handlerConfig) {
+		c.unordered = true
+	}
+}
+
+// WithErrorHandler sets a function called with errors from the subscription, e.g. notifications that could not be
+// decoded or the error that ended the subscription.
+func WithErrorHandler(onError func(error)) HandlerOption {
+	return func(c *handlerConfig) {
+		c.onError = onError
+	}
+}
+
+// handle calls handler for every notification of the delivery on a pool of workers. key picks the worker when
+// ordering is enabled.
+func handle[T any](d *delivery[T], handler func(T), key func(T) uint64, opts []HandlerOption) {
+	config := handlerConfig{workers: 1}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	queues := make([]chan T, config.workers)
+	for i := range queues {
+		queues[i] = make(chan T, 64)
+		if config.unordered && i > 0 {
+			queues[i] = queues[0] // every worker reads the same queue
+		}
+	}
+	var wg sync.WaitGroup
+	wg.Add(config.workers)
+	for i := range config.workers {
+		go func() {
+			defer wg.Done()
+			for value := range queues[i] {
+				handler(value)
+			}
+		}()
+	}
+
+	go func() {
+		errs := d.errs
+		for ch := d.ch; ch != nil; {
+			select {
+			case value, open := <-ch:
+				if !open {
+					ch = nil
+					continue
+				}
+				queue := queues[0]
+				if !config.unordered {
+					queue = queues[key(value)%uint64(len(queues))]
+				}
+				queue <- value
+			case err, open := <-errs:
+				if !open {
+					errs = nil
+					continue
+				}
+				if config.onError != nil {
+					config.onError(err)
+				}
+			}
+		}
+		// pick up the error that ended the subscription
+		for err := range errs {
+			if config.onError != nil {
+				config.onError(err)
+			}
+		}
+		close(queues[0])
+		if !config.unordered {
+			for _, queue := range queues[1:] {
+				close(queue)
+			}
+		}
+		wg.Wait()
+	}()
+}
+
+// accountKey spreads accounts over workers.
+func accountKey(account solana.PublicKey) uint64 {
+	return binary.LittleEndian.Uint64(account[:8])
+}
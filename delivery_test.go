@@ -0,0 +1,76 @@
+package solanastreaming_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+func TestSubscriptionChan(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	sub, err := cli.SubscribeLatestBlock(ctx)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	srv.PushLatestBlock(solanastreaming.LatestBlockNotification{Block: 1})
+	ev := <-sub.Chan()
+	if ev.Block != 1 {
+		t.Fatalf("unexpected notification: %#v", ev)
+	}
+
+	if err := sub.Unsubscribe(ctx); err != nil {
+		t.Fatalf("failed to unsubscribe: %v", err)
+	}
+	for range sub.Chan() {
+	}
+	if sub.Err() != nil {
+		t.Fatalf("unexpected error after unsubscribe: %v", sub.Err())
+	}
+}
+
+func TestSubscriptionOnSwapOrdering(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	sub, err := cli.SubscribeSwaps(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	amms := []solana.PublicKey{solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()}
+	const perAmm = 50
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(perAmm * len(amms))
+	handled := make(map[solana.PublicKey][]uint64)
+	sub.OnSwap(func(ev solanastreaming.SwapNotification) {
+		lock.Lock()
+		handled[ev.Swap.AmmAccount] = append(handled[ev.Swap.AmmAccount], ev.Slot)
+		lock.Unlock()
+		wg.Done()
+	}, solanastreaming.WithWorkers(4))
+
+	for slot := uint64(0); slot < perAmm; slot++ {
+		for _, amm := range amms {
+			srv.PushSwap(solanastreaming.SwapNotification{Slot: slot, Swap: solanastreaming.Swap{AmmAccount: amm}})
+		}
+	}
+	wg.Wait()
+
+	for _, amm := range amms {
+		for i, slot := range handled[amm] {
+			if slot != uint64(i) {
+				t.Fatalf("swaps for %s handled out of order: %v", amm, handled[amm])
+			}
+		}
+	}
+}
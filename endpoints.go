@@ -0,0 +1,155 @@
+package solanastreaming
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Endpoint is a websocket url the client can connect to.
+type Endpoint struct {
+	URL    string
+	Weight int // endpoints with a higher weight are preferred, equal weights keep their order
+}
+
+// EndpointHealth is the result of probing an endpoint, see ProbeEndpoints.
+type EndpointHealth struct {
+	URL       string
+	Latency   time.Duration // time taken to complete the websocket handshake
+	Err       error         // set when the endpoint could not be reached
+	CheckedAt time.Time
+}
+
+// SetEndpoints sets the endpoints to connect to. The client connects to the preferred endpoint and fails over
+// to the next one when a dial fails (including ErrRateLimitExceeded) or an established connection drops.
+// Endpoints are preferred by latency once probed with ProbeEndpoints, then by weight, then by order.
+// It applies to the next connection.
+func (o *Client) SetEndpoints(endpoints ...Endpoint) {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	o.endpoints = sortEndpoints(endpoints)
+	o.endpointHealth = nil
+}
+
+// Endpoint returns the url of the endpoint currently in use, or the preferred endpoint if not connected yet.
+func (o *Client) Endpoint() string {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	if o.currentEndpoint != "" {
+		return o.currentEndpoint
+	}
+	if order := o.endpointOrder(); len(order) > 0 {
+		return order[0]
+	}
+	return ""
+}
+
+// ProbeEndpoints measures the handshake latency of every endpoint concurrently. The results are used to pick
+// the endpoint for the next connection, lowest latency first and unreachable endpoints last. Each probe is a full
+// handshake with the api key that is closed right away, so it counts against the connection rate limit.
+func (o *Client) ProbeEndpoints(ctx context.Context) []EndpointHealth {
+	o.stateLock.Lock()
+	endpoints := o.endpoints
+	o.stateLock.Unlock()
+
+	results := make([]EndpointHealth, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			conn, err := o.dialHost(ctx, endpoint.URL)
+			results[i] = EndpointHealth{
+				URL:       endpoint.URL,
+				Latency:   time.Since(start),
+				Err:       err,
+				CheckedAt: time.Now(),
+			}
+			if err == nil {
+				if closer, ok := conn.(CloseWriter); ok {
+					closer.WriteClose(time.Now().Add(time.Second))
+				}
+				conn.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	o.stateLock.Lock()
+	o.endpointHealth = make(map[string]EndpointHealth, len(results))
+	for _, result := range results {
+		o.endpointHealth[result.URL] = result
+	}
+	o.stateLock.Unlock()
+	return results
+}
+
+// sortEndpoints returns a copy of endpoints ordered by weight.
+func sortEndpoints(endpoints []Endpoint) []Endpoint {
+	sorted := slices.Clone(endpoints)
+	slices.SortStableFunc(sorted, func(a, b Endpoint) int {
+		return cmp.Compare(b.Weight, a.Weight)
+	})
+	return sorted
+}
+
+// endpointOrder returns the endpoint urls in order of preference. Must be called with stateLock held.
+func (o *Client) endpointOrder() []string {
+	endpoints := slices.Clone(o.endpoints)
+	if o.endpointHealth != nil {
+		slices.SortStableFunc(endpoints, func(a, b Endpoint) int {
+			ha, probedA := o.endpointHealth[a.URL]
+			hb, probedB := o.endpointHealth[b.URL]
+			okA, okB := probedA && ha.Err == nil, probedB && hb.Err == nil
+			switch {
+			case okA && okB:
+				return cmp.Compare(ha.Latency, hb.Latency)
+			case okA:
+				return -1
+			case okB:
+				return 1
+			}
+			return 0
+		})
+	}
+	urls := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		urls[i] = endpoint.URL
+	}
+	return urls
+}
+
+// dial connects to the preferred endpoint, failing over to the others in order. When failover is set the
+// endpoint used by the previous connection is tried last.
+func (o *Client) dial(ctx context.Context, failover bool) (Conn, error) {
+	o.stateLock.Lock()
+	order := o.endpointOrder()
+	previous := o.currentEndpoint
+	o.stateLock.Unlock()
+
+	if failover && len(order) > 1 {
+		if i := slices.Index(order, previous); i >= 0 {
+			order = append(order[i+1:], order[:i+1]...)
+		}
+	}
+
+	err := ErrNoEndpoints
+	for _, host := range order {
+		var conn Conn
+		conn, err = o.dialHost(ctx, host)
+		if err != nil {
+			continue
+		}
+		o.stateLock.Lock()
+		o.currentEndpoint = host
+		o.stateLock.Unlock()
+		if host != previous && previous != "" {
+			o.log.Info("wss failover", "from", previous, LogKeyEndpoint, host)
+		}
+		return conn, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,117 @@
+package solanastreaming_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+func TestProbeEndpoints(t *testing.T) {
+	ctx := testContext(t)
+	slow := solanastreamingtest.NewServer()
+	defer slow.Close()
+	fast := solanastreamingtest.NewServer()
+	defer fast.Close()
+	down := solanastreamingtest.NewServer()
+	down.Close()
+	slow.SetHandshakeDelay(100 * time.Millisecond)
+
+	cli := solanastreaming.New("test-key", solanastreaming.WithEndpoints(
+		solanastreaming.Endpoint{URL: down.URL(), Weight: 2},
+		solanastreaming.Endpoint{URL: slow.URL(), Weight: 1},
+		solanastreaming.Endpoint{URL: fast.URL()},
+	))
+	defer cli.Close()
+	if cli.Endpoint() != down.URL() {
+		t.Fatalf("expected the highest weight to be preferred before probing, got %s", cli.Endpoint())
+	}
+
+	results := cli.ProbeEndpoints(ctx)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", results)
+	}
+	for _, result := range results {
+		if (result.Err != nil) != (result.URL == down.URL()) {
+			t.Fatalf("unexpected probe result: %+v", result)
+		}
+		if result.URL == slow.URL() && result.Latency < 100*time.Millisecond {
+			t.Fatalf("handshake delay not measured: %+v", result)
+		}
+	}
+	if cli.Endpoint() != fast.URL() {
+		t.Fatalf("expected the lowest latency to be preferred after probing, got %s", cli.Endpoint())
+	}
+
+	if err := cli.Connect(ctx); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	if cli.Endpoint() != fast.URL() || fast.Connections() != 1 {
+		t.Fatalf("connected to %s, fast server has %d connections", cli.Endpoint(), fast.Connections())
+	}
+	// probe connections are closed right away
+	waitUntil(t, ctx, func() bool { return slow.Connections() == 0 })
+}
+
+func TestEndpointFailover(t *testing.T) {
+	ctx := testContext(t)
+	primary := solanastreamingtest.NewServer()
+	defer primary.Close()
+	secondary := solanastreamingtest.NewServer()
+	defer secondary.Close()
+	down := solanastreamingtest.NewServer()
+	down.Close()
+
+	// an unreachable endpoint is skipped on connect
+	cli := newTestClient(t, primary, solanastreaming.WithEndpoints(
+		solanastreaming.Endpoint{URL: down.URL()},
+		solanastreaming.Endpoint{URL: primary.URL()},
+		solanastreaming.Endpoint{URL: secondary.URL()},
+	))
+	if cli.Endpoint() != primary.URL() {
+		t.Fatalf("expected to connect to the primary, got %s", cli.Endpoint())
+	}
+	sub, err := cli.SubscribeSwaps(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	// the dropped endpoint is tried last even though it is still up
+	primary.Disconnect()
+	if err := secondary.WaitFor(ctx, func() bool { return secondary.Subscriptions("swapSubscribe") == 1 }); err != nil {
+		t.Fatalf("subscription was not replayed on the secondary: %v", err)
+	}
+	if cli.Endpoint() != secondary.URL() {
+		t.Fatalf("expected to fail over to the secondary, got %s", cli.Endpoint())
+	}
+	secondary.PushSwap(solanastreaming.SwapNotification{Slot: 1})
+	if n, err := sub.Receive(ctx); err != nil || n.Slot != 1 {
+		t.Fatalf("unexpected swap after failover: %+v, %v", n, err)
+	}
+
+	// and back again, past the unreachable endpoint
+	secondary.Disconnect()
+	if err := primary.WaitFor(ctx, func() bool { return primary.Subscriptions("swapSubscribe") == 1 }); err != nil {
+		t.Fatalf("subscription was not replayed on the primary: %v", err)
+	}
+	if cli.Endpoint() != primary.URL() {
+		t.Fatalf("expected to fail back to the primary, got %s", cli.Endpoint())
+	}
+}
+
+func TestEndpointsUnreachable(t *testing.T) {
+	down := solanastreamingtest.NewServer()
+	down.Close()
+	cli := solanastreaming.New("test-key", solanastreaming.WithEndpoints(solanastreaming.Endpoint{URL: down.URL()}))
+	defer cli.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := cli.Connect(ctx); err == nil {
+		t.Fatal("connected to an unreachable endpoint")
+	}
+	if cli.Endpoint() != down.URL() {
+		t.Fatalf("unexpected endpoint: %s", cli.Endpoint())
+	}
+}
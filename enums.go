@@ -0,0 +1,126 @@
+package solanastreaming
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// SwapType is the direction of a swap from the wallet's point of view. Values the client does not know are kept as sent.
+type SwapType string
+
+const (
+	SwapTypeBuy  SwapType = "buy"  // the wallet received base token
+	SwapTypeSell SwapType = "sell" // the wallet sent base token
+)
+
+// ParseSwapType returns the known swap type matching s regardless of case, or s unchanged.
+func ParseSwapType(s string) SwapType {
+	for _, t := range []SwapType{SwapTypeBuy, SwapTypeSell} {
+		if strings.EqualFold(strings.TrimSpace(s), string(t)) {
+			return t
+		}
+	}
+	return SwapType(s)
+}
+
+// IsKnown reports whether t is one of the SwapType constants.
+func (t SwapType) IsKnown() bool {
+	return t == SwapTypeBuy || t == SwapTypeSell
+}
+
+func (t SwapType) String() string {
+	return string(t)
+}
+
+// UnmarshalJSON normalizes known values with ParseSwapType.
+func (t *SwapType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t = ParseSwapType(s)
+	return nil
+}
+
+// Exchange identifies a dex or launch platform, e.g. Swap.SourceExchange. Values the client does not know are kept as sent.
+type Exchange string
+
+const (
+	ExchangeRaydium          Exchange = "raydium"
+	ExchangeRaydiumLaunchpad Exchange = "raydium_launchpad" // earlier name of raydium_launchlab
+	ExchangeRaydiumLaunchlab Exchange = "raydium_launchlab"
+	ExchangePumpfun          Exchange = "pumpfun"
+	ExchangePumpswap         Exchange = "pumpswap"
+	ExchangeMeteora          Exchange = "meteora"
+	ExchangeOrca             Exchange = "orca"
+)
+
+// ExchangeInfo describes a known exchange.
+type ExchangeInfo struct {
+	DisplayName  string
+	Launchpad    bool // tokens launch here and migrate to an amm later
+	BondingCurve bool // prices follow a bonding curve instead of a liquidity pool
+}
+
+var exchanges = map[Exchange]ExchangeInfo{
+	ExchangeRaydium:          {DisplayName: "Raydium"},
+	ExchangeRaydiumLaunchpad: {DisplayName: "Raydium LaunchLab", Launchpad: true, BondingCurve: true},
+	ExchangeRaydiumLaunchlab: {DisplayName: "Raydium LaunchLab", Launchpad: true, BondingCurve: true},
+	ExchangePumpfun:          {DisplayName: "Pump.fun", Launchpad: true, BondingCurve: true},
+	ExchangePumpswap:         {DisplayName: "PumpSwap"},
+	ExchangeMeteora:          {DisplayName: "Meteora"},
+	ExchangeOrca:             {DisplayName: "Orca"},
+}
+
+// ParseExchange returns the known exchange matching s regardless of case, or s unchanged.
+func ParseExchange(s string) Exchange {
+	normalized := Exchange(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := exchanges[normalized]; ok {
+		return normalized
+	}
+	return Exchange(s)
+}
+
+// IsKnown reports whether e is one of the Exchange constants.
+func (e Exchange) IsKnown() bool {
+	_, ok := exchanges[e]
+	return ok
+}
+
+// Info returns the metadata of a known exchange.
+func (e Exchange) Info() (ExchangeInfo, bool) {
+	info, ok := exchanges[e]
+	return info, ok
+}
+
+// DisplayName returns a human readable name, the raw value for unknown exchanges.
+func (e Exchange) DisplayName() string {
+	if info, ok := exchanges[e]; ok {
+		return info.DisplayName
+	}
+	return string(e)
+}
+
+// IsLaunchpad reports whether e is a known launch platform.
+func (e Exchange) IsLaunchpad() bool {
+	return exchanges[e].Launchpad
+}
+
+// IsBondingCurve reports whether e is a known exchange that prices tokens on a bonding curve.
+func (e Exchange) IsBondingCurve() bool {
+	return exchanges[e].BondingCurve
+}
+
+func (e Exchange) String() string {
+	return string(e)
+}
+
+// UnmarshalJSON normalizes known values with ParseExchange.
+func (e *Exchange) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*e = ParseExchange(s)
+	return nil
+}
@@ -0,0 +1,50 @@
+package solanastreaming_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+)
+
+func TestEnums(t *testing.T) {
+	var swap solanastreaming.Swap
+	if err := json.Unmarshal([]byte(`{"sourceExchange":"PumpFun","swapType":"Buy"}`), &swap); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if swap.SwapType != solanastreaming.SwapTypeBuy || swap.SourceExchange != solanastreaming.ExchangePumpfun {
+		t.Fatalf("known values not normalized: %+v", swap)
+	}
+	if !swap.SourceExchange.IsLaunchpad() || !swap.SourceExchange.IsBondingCurve() || swap.SourceExchange.DisplayName() != "Pump.fun" {
+		t.Fatalf("unexpected exchange metadata: %+v", swap.SourceExchange)
+	}
+
+	var pair solanastreaming.Pair
+	if err := json.Unmarshal([]byte(`{"sourceExchange":"future_dex","migration":"raydium_launchpad"}`), &pair); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if pair.SourceExchange.IsKnown() || pair.SourceExchange != "future_dex" || pair.SourceExchange.DisplayName() != "future_dex" {
+		t.Fatalf("unknown exchange not preserved: %q", pair.SourceExchange)
+	}
+	if pair.Migration != solanastreaming.ExchangeRaydiumLaunchpad || !pair.Migration.IsKnown() {
+		t.Fatalf("unexpected migration: %q", pair.Migration)
+	}
+	if info, ok := solanastreaming.ExchangeOrca.Info(); !ok || info.Launchpad || info.BondingCurve {
+		t.Fatalf("unexpected orca metadata: %+v", info)
+	}
+
+	if solanastreaming.ParseSwapType(" SELL ") != solanastreaming.SwapTypeSell {
+		t.Fatal("swap type not parsed")
+	}
+	if unknown := solanastreaming.ParseSwapType("transfer"); unknown.IsKnown() || unknown != "transfer" {
+		t.Fatalf("unknown swap type not preserved: %q", unknown)
+	}
+	out, err := json.Marshal(solanastreaming.Swap{SwapType: "transfer", SourceExchange: solanastreaming.ExchangeMeteora})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var again solanastreaming.Swap
+	if err := json.Unmarshal(out, &again); err != nil || again.SwapType != "transfer" || again.SourceExchange != solanastreaming.ExchangeMeteora {
+		t.Fatalf("round trip changed the values: %s", out)
+	}
+}
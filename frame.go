@@ -0,0 +1,123 @@
+package solanastreaming
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// maxPooledFrame is the largest frame buffer that is reused, bigger ones are left to the garbage collector.
+const maxPooledFrame = 1 << 20
+
+// frameBuffer holds a received frame. The params of a notification point into it until the notification is
+// decoded or dropped, then both go back to their pools.
+type frameBuffer struct {
+	data    []byte
+	foreign bool // data was returned by Conn.ReadFrame, which may still use it, so it is never reused
+}
+
+var (
+	framePool   = sync.Pool{New: func() any { return &frameBuffer{data: make([]byte, 0, 4096)} }}
+	messagePool = sync.Pool{New: func() any { return new(wireMessage) }}
+)
+
+// frameReader is implemented by connections that can read a frame into a buffer they are handed.
+type frameReader interface {
+	readFrameInto(buf []byte) ([]byte, error)
+}
+
+// readFrame reads the next frame, into a pooled buffer if conn supports it.
+func readFrame(conn Conn) (*frameBuffer, error) {
+	r, ok := conn.(frameReader)
+	if !ok {
+		data, err := conn.ReadFrame()
+		return &frameBuffer{data: data, foreign: true}, err
+	}
+	frame := framePool.Get().(*frameBuffer)
+	var err error
+	frame.data, err = r.readFrameInto(frame.data[:0])
+	if err != nil {
+		framePool.Put(frame)
+		return nil, err
+	}
+	return frame, nil
+}
+
+// notificationMethods interns the method names the server sends so routing a frame does not allocate them.
+var notificationMethods = map[string]string{
+	"swapNotification":        "swapNotification",
+	"newPairNotification":     "newPairNotification",
+	"latestBlockNotification": "latestBlockNotification",
+}
+
+// parseFrame routes a frame without decoding it. Only the routing fields are parsed, params and result are kept as
+// slices of the frame for whoever the message is routed to and anything else is skipped.
+func parseFrame(frame *frameBuffer) (*wireMessage, error) {
+	m := messagePool.Get().(*wireMessage)
+	m.frame = frame
+	r := jsonReader{data: frame.data}
+	for i := 0; ; i++ {
+		key, ok, err := r.member(i)
+		if err == nil && !ok {
+			if err = r.end(); err == nil {
+				return m, nil
+			}
+		}
+		if err != nil {
+			m.release()
+			return nil, err
+		}
+		switch string(key) {
+		case "id":
+			m.ID, err = r.int()
+		case "subscription_id":
+			m.SubscriptionID, err = r.uint()
+		case "method":
+			m.Method, err = r.method()
+		case "params":
+			m.Params = nil
+			if !r.null() {
+				m.params, err = r.value()
+				m.Params = &m.params
+			}
+		case "result":
+			m.Result, err = r.value()
+		case "error":
+			m.Error = nil
+			if !r.null() {
+				var raw []byte
+				if raw, err = r.value(); err == nil {
+					m.Error = new(wireError)
+					err = json.Unmarshal(raw, m.Error)
+				}
+			}
+		default:
+			err = r.skip()
+		}
+		if err != nil {
+			err = fmt.Errorf("%s: %w", key, err)
+			m.release()
+			return nil, err
+		}
+	}
+}
+
+// method reads a method name, interning the known ones.
+func (r *jsonReader) method() (string, error) {
+	text, err := r.text()
+	if method, ok := notificationMethods[string(text)]; ok {
+		return method, err
+	}
+	return string(text), err
+}
+
+// release returns a message read by the read loop and its frame to their pools. It must only be called once the
+// message is done with, which for a notification is after decoding or dropping it. Responses to requests are left
+// to the garbage collector as the requester keeps them.
+func (m *wireMessage) release() {
+	if frame := m.frame; frame != nil && !frame.foreign && cap(frame.data) <= maxPooledFrame {
+		framePool.Put(frame)
+	}
+	*m = wireMessage{}
+	messagePool.Put(m)
+}
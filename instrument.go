@@ -0,0 +1,43 @@
+package solanastreaming
+
+import "context"
+
+// Operation names passed to Instrumentation.
+const (
+	OperationConnect      = "connect"
+	OperationSubscribe    = "subscribe"
+	OperationRequest      = "request" // every request that waits for a response, nested in the operations above
+	OperationUpdateParams = "update_params"
+	OperationUnsubscribe  = "unsubscribe"
+)
+
+// Operation describes a client operation. Fields the client only learns while the operation runs, such as the
+// SubscriptionID of a subscribe, are filled in before the end function is called.
+type Operation struct {
+	Name           string
+	Method         string // wire method, e.g. "swapSubscribe"
+	RequestID      int    // set for OperationRequest
+	SubscriptionID uint
+	Endpoint       string // set for OperationConnect once an endpoint was dialed
+}
+
+// Instrumentation is notified when the client starts an operation, e.g. to trace it. The returned context is used
+// for the rest of the operation and end is called with its outcome. See the solanastreamingotel package.
+type Instrumentation interface {
+	StartOperation(ctx context.Context, op *Operation) (context.Context, func(err error))
+}
+
+// WithInstrumentation sets the instrumentation notified about client operations.
+func WithInstrumentation(instrumentation Instrumentation) Option {
+	return func(c *Client) {
+		c.instrumentation = instrumentation
+	}
+}
+
+// startOperation starts op on the instrumentation if there is one.
+func (o *Client) startOperation(ctx context.Context, op *Operation) (context.Context, func(err error)) {
+	if o.instrumentation == nil {
+		return ctx, func(error) {}
+	}
+	return o.instrumentation.StartOperation(ctx, op)
+}
@@ -0,0 +1,102 @@
+package solanastreaming
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// all yields notifications until ctx is done or the subscription ends. Errors that end the subscription, such as a
+// failed connection, are yielded once before stopping; other errors, such as a notification that could not be
+// decoded, are yielded and iteration continues.
+func all[T any](ctx context.Context, sub subscription[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			value, err := receive(ctx, sub)
+			if err == nil {
+				if !yield(value, nil) {
+					return
+				}
+				continue
+			}
+			if ctx.Err() != nil || errors.Is(err, ErrSubscriptionClosed) {
+				return
+			}
+			if !yield(value, err) || sub.ended() {
+				return
+			}
+		}
+	}
+}
+
+// ended reports whether the subscription can not deliver any more notifications.
+func (s subscription[T]) ended() bool {
+	select {
+	case <-s.active.done:
+		return s.active.buffered() == 0
+	default:
+	}
+	return s.client != nil && s.client.err() != nil && s.active.buffered() == 0
+}
+
+// Filter yields the notifications of seq for which keep returns true. Errors are passed through.
+func Filter[T any](seq iter.Seq2[T, error], keep func(T) bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for value, err := range seq {
+			if err == nil && !keep(value) {
+				continue
+			}
+			if !yield(value, err) {
+				return
+			}
+		}
+	}
+}
+
+// Map yields fn applied to the notifications of seq. Errors are passed through with the zero value of U.
+func Map[T, U any](seq iter.Seq2[T, error], fn func(T) U) iter.Seq2[U, error] {
+	return func(yield func(U, error) bool) {
+		for value, err := range seq {
+			var mapped U
+			if err == nil {
+				mapped = fn(value)
+			}
+			if !yield(mapped, err) {
+				return
+			}
+		}
+	}
+}
+
+// Batch groups the notifications of seq into slices of size. A shorter batch is yielded before an error and when
+// seq ends. Batch panics if size is less than 1.
+func Batch[T any](seq iter.Seq2[T, error], size int) iter.Seq2[[]T, error] {
+	if size < 1 {
+		panic("solanastreaming: Batch size cannot be less than 1")
+	}
+	return func(yield func([]T, error) bool) {
+		batch := make([]T, 0, size)
+		for value, err := range seq {
+			if err != nil {
+				if len(batch) > 0 && !yield(batch, nil) {
+					return
+				}
+				batch = make([]T, 0, size)
+				if !yield(nil, err) {
+					return
+				}
+				continue
+			}
+			batch = append(batch, value)
+			if len(batch) >= size {
+				if !yield(batch, nil) {
+					return
+				}
+				batch = make([]T, 0, size)
+			}
+		}
+		if len(batch) > 0 {
+			yield(batch, nil)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package solanastreaming_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+func TestSubscriptionAll(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	sub, err := cli.SubscribeLatestBlock(ctx)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	for block := uint64(1); block <= 8; block++ {
+		srv.PushLatestBlock(solanastreaming.LatestBlockNotification{Block: block})
+	}
+
+	even := solanastreaming.Filter(sub.All(ctx), func(ev solanastreaming.LatestBlockNotification) bool {
+		return ev.Block%2 == 0
+	})
+	blocks := solanastreaming.Map(even, func(ev solanastreaming.LatestBlockNotification) uint64 {
+		return ev.Block
+	})
+	var batches [][]uint64
+	for batch, err := range solanastreaming.Batch(blocks, 2) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		batches = append(batches, batch)
+		if len(batches) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(batches[0], []uint64{2, 4}) || !slices.Equal(batches[1], []uint64{6, 8}) {
+		t.Fatalf("unexpected batches: %v", batches)
+	}
+
+	// unsubscribing ends the iteration without an error
+	go sub.Unsubscribe(ctx)
+	for _, err := range sub.All(ctx) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestBatch(t *testing.T) {
+	seq := func(yield func(int, error) bool) {
+		for _, v := range []int{1, 2, 3} {
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if !yield(0, errors.New("decode failed")) {
+			return
+		}
+		yield(4, nil)
+	}
+	var batches [][]int
+	var errs int
+	for batch, err := range solanastreaming.Batch(seq, 2) {
+		if err != nil {
+			errs++
+			continue
+		}
+		batches = append(batches, batch)
+	}
+	if len(batches) != 3 || !slices.Equal(batches[0], []int{1, 2}) || !slices.Equal(batches[1], []int{3}) || !slices.Equal(batches[2], []int{4}) || errs != 1 {
+		t.Fatalf("unexpected batches %v and %d errors", batches, errs)
+	}
+
+	var singles [][]int
+	for batch := range solanastreaming.Batch(seq, 1) {
+		if batch != nil {
+			singles = append(singles, batch)
+		}
+	}
+	if len(singles) != 4 {
+		t.Fatalf("unexpected batches of 1: %v", singles)
+	}
+
+	for _, size := range []int{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("no panic for size %d", size)
+				}
+			}()
+			solanastreaming.Batch(seq, size)
+		}()
+	}
+}
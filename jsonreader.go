@@ -0,0 +1,337 @@
+package solanastreaming
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// maxJSONDepth limits how deeply skipped values may nest, the same limit encoding/json applies.
+const maxJSONDepth = 10000
+
+var errUnexpectedEnd = errors.New("unexpected end of JSON input")
+
+// jsonReader reads JSON values in place. The read loop and the notification decoders use it instead of
+// encoding/json: values are parsed without reflection, strings are only copied when they are kept and members
+// nobody asked for are skipped without being decoded.
+type jsonReader struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (r *jsonReader) syntaxError(msg string) error {
+	if r.pos >= len(r.data) {
+		return errUnexpectedEnd
+	}
+	return fmt.Errorf("invalid character %q at offset %d: %s", r.data[r.pos], r.pos, msg)
+}
+
+// peek skips whitespace and returns the next byte without consuming it, 0 at the end of the input.
+func (r *jsonReader) peek() byte {
+	for r.pos < len(r.data) {
+		switch c := r.data[r.pos]; c {
+		case ' ', '\t', '\n', '\r':
+			r.pos++
+		default:
+			return c
+		}
+	}
+	return 0
+}
+
+// end checks that nothing but whitespace follows the value read last.
+func (r *jsonReader) end() error {
+	if r.peek(); r.pos < len(r.data) {
+		return r.syntaxError("after top-level value")
+	}
+	return nil
+}
+
+// literal consumes word if it comes next.
+func (r *jsonReader) literal(word string) bool {
+	if r.peek() != word[0] || len(r.data)-r.pos < len(word) || string(r.data[r.pos:r.pos+len(word)]) != word {
+		return false
+	}
+	r.pos += len(word)
+	return true
+}
+
+// null consumes a null and reports whether there was one.
+func (r *jsonReader) null() bool {
+	return r.literal("null")
+}
+
+// member reads the key of the next member of an object, i counts the members read so far. ok is false once the
+// object has ended. The value has to be read or skipped before the next call.
+func (r *jsonReader) member(i int) (key []byte, ok bool, err error) {
+	if i == 0 {
+		if r.peek() != '{' {
+			return nil, false, r.syntaxError("looking for beginning of object")
+		}
+		r.pos++
+		if r.peek() == '}' {
+			r.pos++
+			return nil, false, nil
+		}
+	} else {
+		switch r.peek() {
+		case '}':
+			r.pos++
+			return nil, false, nil
+		case ',':
+			r.pos++
+		default:
+			return nil, false, r.syntaxError("after object key:value pair")
+		}
+	}
+	key, _, err = r.rawString()
+	if err != nil {
+		return nil, false, err
+	}
+	if r.peek() != ':' {
+		return nil, false, r.syntaxError("after object key")
+	}
+	r.pos++
+	return key, true, nil
+}
+
+// element reports whether the array has another element, i counts the elements read so far. The element has to be
+// read or skipped before the next call.
+func (r *jsonReader) element(i int) (bool, error) {
+	if i == 0 {
+		if r.peek() != '[' {
+			return false, r.syntaxError("looking for beginning of array")
+		}
+		r.pos++
+		if r.peek() == ']' {
+			r.pos++
+			return false, nil
+		}
+		return true, nil
+	}
+	switch r.peek() {
+	case ',':
+		r.pos++
+		return true, nil
+	case ']':
+		r.pos++
+		return false, nil
+	}
+	return false, r.syntaxError("after array element")
+}
+
+// rawString reads a string and returns its contents as they appear in the input. escaped reports whether they
+// contain escape sequences.
+func (r *jsonReader) rawString() (raw []byte, escaped bool, err error) {
+	if r.peek() != '"' {
+		return nil, false, r.syntaxError("looking for beginning of string")
+	}
+	start := r.pos + 1
+	for i := start; i < len(r.data); i++ {
+		switch c := r.data[i]; {
+		case c == '"':
+			r.pos = i + 1
+			return r.data[start:i], escaped, nil
+		case c == '\\':
+			escaped = true
+			i++
+		case c < 0x20:
+			r.pos = i
+			return nil, false, r.syntaxError("in string literal")
+		}
+	}
+	r.pos = len(r.data)
+	return nil, false, errUnexpectedEnd
+}
+
+// text reads a string and returns its unescaped contents. They point into the input unless the string had to be
+// unescaped, so they must be copied to be kept. null reads as nothing.
+func (r *jsonReader) text() ([]byte, error) {
+	if r.null() {
+		return nil, nil
+	}
+	start := r.pos
+	raw, escaped, err := r.rawString()
+	if err != nil || (!escaped && utf8.Valid(raw)) {
+		return raw, err
+	}
+	// escapes and invalid utf-8 are rare, leave them to encoding/json to get them exactly right
+	var s string
+	if err := json.Unmarshal(r.data[start:r.pos], &s); err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// string reads a string, null reads as "".
+func (r *jsonReader) string() (string, error) {
+	text, err := r.text()
+	return string(text), err
+}
+
+// number reads a number and returns its text.
+func (r *jsonReader) number() ([]byte, error) {
+	r.peek()
+	start, i := r.pos, r.pos
+	if i < len(r.data) && r.data[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(r.data) && r.data[i] == '0':
+		i++
+	case i < len(r.data) && r.data[i] >= '1' && r.data[i] <= '9':
+		i = r.digits(i)
+	default:
+		r.pos = i
+		return nil, r.syntaxError("looking for number")
+	}
+	if i < len(r.data) && r.data[i] == '.' {
+		if i = r.digits(i + 1); r.data[i-1] == '.' {
+			r.pos = i
+			return nil, r.syntaxError("after decimal point in numeric literal")
+		}
+	}
+	if i < len(r.data) && (r.data[i] == 'e' || r.data[i] == 'E') {
+		i++
+		if i < len(r.data) && (r.data[i] == '+' || r.data[i] == '-') {
+			i++
+		}
+		exponent := i
+		if i = r.digits(i); i == exponent {
+			r.pos = i
+			return nil, r.syntaxError("in exponent of numeric literal")
+		}
+	}
+	r.pos = i
+	return r.data[start:i], nil
+}
+
+// digits returns the index of the first byte from i on that is not a digit.
+func (r *jsonReader) digits(i int) int {
+	for i < len(r.data) && r.data[i] >= '0' && r.data[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+// uint64 reads an unsigned integer, null reads as 0.
+func (r *jsonReader) uint64() (uint64, error) {
+	if r.null() {
+		return 0, nil
+	}
+	number, err := r.number()
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, c := range number {
+		if c < '0' || c > '9' || n > (1<<64-1-uint64(c-'0'))/10 {
+			return 0, fmt.Errorf("cannot unmarshal number %s into an unsigned integer", number)
+		}
+		n = n*10 + uint64(c-'0')
+	}
+	return n, nil
+}
+
+// uint reads an unsigned integer that fits a uint, null reads as 0.
+func (r *jsonReader) uint() (uint, error) {
+	n, err := r.uint64()
+	if err == nil && uint64(uint(n)) != n {
+		return 0, fmt.Errorf("cannot unmarshal number %d into uint", n)
+	}
+	return uint(n), err
+}
+
+// int reads an integer that fits an int, null reads as 0.
+func (r *jsonReader) int() (int, error) {
+	if r.null() {
+		return 0, nil
+	}
+	number, err := r.number()
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(string(number))
+	if err != nil {
+		return 0, fmt.Errorf("cannot unmarshal number %s into int", number)
+	}
+	return n, nil
+}
+
+// float64 reads a number, null reads as nil.
+func (r *jsonReader) float64() (*float64, error) {
+	if r.null() {
+		return nil, nil
+	}
+	number, err := r.number()
+	if err != nil {
+		return nil, err
+	}
+	f, err := strconv.ParseFloat(string(number), 64)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal number %s into float64", number)
+	}
+	return &f, nil
+}
+
+// value reads any value and returns its text.
+func (r *jsonReader) value() ([]byte, error) {
+	r.peek()
+	start := r.pos
+	if err := r.skip(); err != nil {
+		return nil, err
+	}
+	return r.data[start:r.pos], nil
+}
+
+// skip reads over any value, checking that it is well formed.
+func (r *jsonReader) skip() error {
+	switch c := r.peek(); {
+	case c == '{':
+		return r.nested(func() error {
+			for i := 0; ; i++ {
+				_, ok, err := r.member(i)
+				if err != nil || !ok {
+					return err
+				}
+				if err := r.skip(); err != nil {
+					return err
+				}
+			}
+		})
+	case c == '[':
+		return r.nested(func() error {
+			for i := 0; ; i++ {
+				ok, err := r.element(i)
+				if err != nil || !ok {
+					return err
+				}
+				if err := r.skip(); err != nil {
+					return err
+				}
+			}
+		})
+	case c == '"':
+		_, _, err := r.rawString()
+		return err
+	case c == '-' || (c >= '0' && c <= '9'):
+		_, err := r.number()
+		return err
+	case r.literal("true"), r.literal("false"), r.literal("null"):
+		return nil
+	}
+	return r.syntaxError("looking for beginning of value")
+}
+
+// nested reads an object or array with read, failing if they nest too deeply.
+func (r *jsonReader) nested(read func() error) error {
+	if r.depth++; r.depth > maxJSONDepth {
+		return errors.New("exceeded max depth")
+	}
+	err := read()
+	r.depth--
+	return err
+}
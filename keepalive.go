@@ -0,0 +1,91 @@
+package solanastreaming
+
+import (
+	"context"
+	"time"
+)
+
+// KeepaliveConfig controls how the client detects dead connections.
+type KeepaliveConfig struct {
+	PingInterval time.Duration // how often a ping is sent, 0 or less disables pings
+	ReadTimeout  time.Duration // the connection is dropped when nothing (notification, response or pong) is read for this long, 0 or less disables the deadline
+}
+
+// DefaultKeepaliveConfig returns the keepalive settings used by New.
+func DefaultKeepaliveConfig() KeepaliveConfig {
+	return KeepaliveConfig{
+		PingInterval: 15 * time.Second,
+		ReadTimeout:  45 * time.Second,
+	}
+}
+
+// SetKeepalive changes the ping interval and read timeout. It applies to the next connection.
+func (o *Client) SetKeepalive(config KeepaliveConfig) {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	o.keepalive = config
+}
+
+// RTT returns the round trip time measured by the most recent ping, or 0 if no pong has been received yet.
+func (o *Client) RTT() time.Duration {
+	return time.Duration(o.rtt.Load())
+}
+
+// keepaliveConn tracks liveness for a single connection.
+type keepaliveConn struct {
+	conn   Conn
+	config KeepaliveConfig
+	done   chan struct{}
+}
+
+// startKeepalive sets the initial read deadline and starts pinging.
+// The returned keepaliveConn must be stopped once the connection is no longer read.
+func (o *Client) startKeepalive(conn Conn) *keepaliveConn {
+	o.stateLock.Lock()
+	config := o.keepalive
+	o.stateLock.Unlock()
+
+	k := &keepaliveConn{
+		conn:   conn,
+		config: config,
+		done:   make(chan struct{}),
+	}
+	k.alive()
+	if config.PingInterval > 0 {
+		go k.pingLoop(o)
+	}
+	return k
+}
+
+// alive pushes the read deadline back, called whenever anything is read from the connection.
+func (k *keepaliveConn) alive() {
+	if k.config.ReadTimeout > 0 {
+		k.conn.SetReadDeadline(time.Now().Add(k.config.ReadTimeout))
+	}
+}
+
+func (k *keepaliveConn) pingLoop(o *Client) {
+	ticker := time.NewTicker(k.config.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-k.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), k.config.PingInterval)
+			rtt, err := k.conn.Ping(ctx)
+			cancel()
+			if err != nil {
+				// a broken connection is picked up by the read deadline
+				o.log.Error("wss ping failed", LogKeyError, err)
+				continue
+			}
+			o.rtt.Store(int64(rtt))
+			k.alive()
+		}
+	}
+}
+
+func (k *keepaliveConn) stop() {
+	close(k.done)
+}
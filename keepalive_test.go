@@ -0,0 +1,109 @@
+package solanastreaming_test
+
+import (
+	"testing"
+	"time"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+func TestKeepaliveRTT(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv, solanastreaming.WithKeepalive(solanastreaming.KeepaliveConfig{
+		PingInterval: 10 * time.Millisecond,
+		ReadTimeout:  time.Second,
+	}))
+
+	waitUntil(t, ctx, func() bool { return cli.RTT() > 0 })
+	if stats := cli.Stats(); stats.RTT <= 0 {
+		t.Fatalf("rtt missing from stats: %+v", stats)
+	}
+}
+
+func TestKeepalivePongsExtendReadDeadline(t *testing.T) {
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv, solanastreaming.WithKeepalive(solanastreaming.KeepaliveConfig{
+		PingInterval: 10 * time.Millisecond,
+		ReadTimeout:  100 * time.Millisecond,
+	}))
+	reconnected := make(chan solanastreaming.ReconnectEvent, 1)
+	cli.OnReconnect(func(ev solanastreaming.ReconnectEvent) { reconnected <- ev })
+
+	// nothing but pongs is read, they alone have to keep the connection
+	select {
+	case ev := <-reconnected:
+		t.Fatalf("idle connection with pongs was dropped: %v", ev.Err)
+	case <-time.After(500 * time.Millisecond):
+	}
+	if cli.State() != solanastreaming.StateConnected {
+		t.Fatalf("unexpected state: %s", cli.State())
+	}
+}
+
+func TestKeepaliveReadDeadline(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	// no pings, so an idle connection runs into the read deadline
+	cli := newTestClient(t, srv, solanastreaming.WithKeepalive(solanastreaming.KeepaliveConfig{
+		ReadTimeout: 50 * time.Millisecond,
+	}))
+	reconnected := make(chan solanastreaming.ReconnectEvent, 1)
+	cli.OnReconnect(func(ev solanastreaming.ReconnectEvent) {
+		select {
+		case reconnected <- ev:
+		default:
+		}
+	})
+
+	select {
+	case ev := <-reconnected:
+		if ev.Err == nil {
+			t.Fatal("reconnect without the read error")
+		}
+	case <-ctx.Done():
+		t.Fatal("idle connection was not dropped after the read timeout")
+	}
+}
+
+func TestKeepaliveDeadPeer(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv, solanastreaming.WithKeepalive(solanastreaming.KeepaliveConfig{
+		PingInterval: 10 * time.Millisecond,
+		ReadTimeout:  100 * time.Millisecond,
+	}))
+	reconnected := make(chan solanastreaming.ReconnectEvent, 1)
+	cli.OnReconnect(func(ev solanastreaming.ReconnectEvent) {
+		select {
+		case reconnected <- ev:
+		default:
+		}
+	})
+	sub, err := cli.SubscribeSwaps(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	srv.DropPings(true)
+	select {
+	case <-reconnected:
+	case <-ctx.Done():
+		t.Fatal("client did not reconnect after missing pongs")
+	}
+	srv.DropPings(false)
+
+	if err := srv.WaitFor(ctx, func() bool { return srv.Subscriptions("swapSubscribe") == 1 }); err != nil {
+		t.Fatal("subscription was not replayed")
+	}
+	srv.PushSwap(solanastreaming.SwapNotification{Slot: 7})
+	ev, err := sub.Receive(ctx)
+	if err != nil || ev.Slot != 7 {
+		t.Fatalf("unexpected notification after reconnect: %v %+v", err, ev)
+	}
+}
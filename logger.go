@@ -0,0 +1,109 @@
+package solanastreaming
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"os"
+	"sync/atomic"
+)
+
+// Logger receives the client logs. Args are alternating keys and values as in log/slog, e.g.
+// "conn_id", 1, "subscription_id", 42. *slog.Logger implements Logger, see solanastreaminglogrus for logrus.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// LevelOff is a log level above every other level, it turns logging off.
+const LevelOff = slog.Level(math.MaxInt)
+
+// Keys of the structured attributes the client logs with.
+const (
+	LogKeyConnID         = "conn_id"
+	LogKeySubscriptionID = "subscription_id"
+	LogKeyMethod         = "method"
+	LogKeyRequestID      = "request_id"
+	LogKeyEndpoint       = "endpoint"
+	LogKeyError          = "err"
+)
+
+var _ Logger = (*slog.Logger)(nil)
+
+// NopLogger returns a Logger that discards everything.
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+func (nopLogger) Enabled(context.Context, slog.Level) bool { return false }
+
+// levelLogger drops records below its level before passing them on. Both can be changed while the client runs.
+type levelLogger struct {
+	logger atomic.Pointer[Logger]
+	level  slog.LevelVar
+}
+
+// newLevelLogger returns the default logger, which writes text to stderr once SetLogLevel is called.
+func newLevelLogger() *levelLogger {
+	var logger Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	l := &levelLogger{}
+	l.logger.Store(&logger)
+	l.level.Set(LevelOff)
+	return l
+}
+
+// set replaces the logger and passes every record to it, it applies its own level.
+func (l *levelLogger) set(logger Logger) {
+	if logger == nil {
+		logger = NopLogger()
+	}
+	l.logger.Store(&logger)
+	l.level.Set(slog.LevelDebug)
+}
+
+// debugEnabled reports whether a debug record would be logged, so frames are only formatted when they are.
+// Loggers that have an Enabled method like *slog.Logger are asked as well.
+func (l *levelLogger) debugEnabled() bool {
+	if l.level.Level() > slog.LevelDebug {
+		return false
+	}
+	if logger, ok := (*l.logger.Load()).(interface {
+		Enabled(context.Context, slog.Level) bool
+	}); ok {
+		return logger.Enabled(context.Background(), slog.LevelDebug)
+	}
+	return true
+}
+
+func (l *levelLogger) Debug(msg string, args ...any) {
+	if l.level.Level() <= slog.LevelDebug {
+		(*l.logger.Load()).Debug(msg, args...)
+	}
+}
+
+func (l *levelLogger) Info(msg string, args ...any) {
+	if l.level.Level() <= slog.LevelInfo {
+		(*l.logger.Load()).Info(msg, args...)
+	}
+}
+
+func (l *levelLogger) Warn(msg string, args ...any) {
+	if l.level.Level() <= slog.LevelWarn {
+		(*l.logger.Load()).Warn(msg, args...)
+	}
+}
+
+func (l *levelLogger) Error(msg string, args ...any) {
+	if l.level.Level() <= slog.LevelError {
+		(*l.logger.Load()).Error(msg, args...)
+	}
+}
@@ -0,0 +1,81 @@
+package solanastreaming_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of the client goroutines.
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) records(t *testing.T) []map[string]any {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestClientLogger(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+
+	var out syncBuffer
+	cli := solanastreaming.New("test-key",
+		solanastreaming.WithHost(srv.URL()),
+		solanastreaming.WithLogger(slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+	)
+	if err := cli.Connect(ctx); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer cli.Close()
+	if _, err := cli.SubscribeSwaps(ctx, nil); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	var connected, sent bool
+	for _, record := range out.records(t) {
+		switch record["msg"] {
+		case "wss connected":
+			connected = record[solanastreaming.LogKeyConnID] == 1.0 && record[solanastreaming.LogKeyEndpoint] == srv.URL()
+		case "wss send":
+			sent = record[solanastreaming.LogKeyMethod] == "swapSubscribe" && record[solanastreaming.LogKeyRequestID] != nil
+		}
+	}
+	if !connected || !sent {
+		t.Fatalf("structured attributes missing: %s", out.buf.String())
+	}
+
+	// the client level applies on top of the logger
+	cli.SetLogLevel(solanastreaming.LevelOff)
+	before := len(out.records(t))
+	if _, err := cli.SubscribeNewPairs(ctx, nil); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	if after := len(out.records(t)); after != before {
+		t.Fatalf("logged %d records with logging off", after-before)
+	}
+}
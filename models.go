@@ -3,69 +3,115 @@ package solanastreaming
 import (
 	"encoding/json"
 	"errors"
-	"math/big"
+	"fmt"
+	"time"
 
 	"github.com/gagliardetto/solana-go"
 )
 
 var (
+	ErrClientClosed       = errors.New("client closed")
 	ErrConnectFirst       = errors.New("connect first")
+	ErrNoEndpoints        = errors.New("no endpoints")
 	ErrNoSubscription     = errors.New("no subscription")
 	ErrRateLimitExceeded  = errors.New("rate limit exceeded")
+	ErrRequestTimeout     = errors.New("timeout")
+	ErrSlowConsumer       = errors.New("slow consumer")
 	ErrSubscriptionClosed = errors.New("subscription closed")
 )
 
-type receiverType int
-
-const (
-	receiverTypeByRequestID receiverType = iota
-	receiverTypeBySubscriptionID
-)
-
-type receiver struct {
-	Type  receiverType
-	Value int
-}
-
 type wireMessage struct {
 	ID             int              `json:"id"`
 	SubscriptionID uint             `json:"subscription_id,omitempty"` // for reeiving subscription notifications
 	Method         string           `json:"method"`                    // method or notification name
 	Params         *json.RawMessage `json:"params,omitempty"`          // notification body or request params
 	Result         json.RawMessage  `json:"result"`                    // response to subscription messages
-	Error          *struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
-	} `json:"error,omitempty"`
+	Error          *wireError       `json:"error,omitempty"`
+
+	receivedAt time.Time       // when the frame was read
+	size       int             // length of the frame
+	generation uint64          // params generation of the subscription when delivered
+	frame      *frameBuffer    // the pooled frame Params and Result point into, see release
+	params     json.RawMessage // backs Params so routing a frame does not allocate
+}
+
+type wireError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ServerError is an error frame sent by the server. Errors for a subscription are returned by its Receive,
+// errors that can not be attributed to a request or subscription are passed to the OnError callbacks.
+type ServerError struct {
+	Code           int
+	Message        string
+	RequestID      int  // the request the error answers, 0 if none
+	SubscriptionID uint // the subscription the error belongs to, 0 if none
+}
+
+func newServerError(message *wireMessage) *ServerError {
+	return &ServerError{
+		Code:           message.Error.Code,
+		Message:        message.Error.Message,
+		RequestID:      message.ID,
+		SubscriptionID: message.SubscriptionID,
+	}
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("solana wss error: %d %s", e.Code, e.Message)
 }
 
 type LatestBlockNotification struct {
 	Block     uint64 `json:"block"`
-	BlockTime uint64 `json:"blockTime"`
+	BlockTime uint64 `json:"blockTime"` // unix seconds, see BlockTimestamp
 }
 
+func (n LatestBlockNotification) blockTime() uint64 { return n.BlockTime }
+
+// BlockTimestamp returns BlockTime as a time, the zero time if it is not set.
+func (n LatestBlockNotification) BlockTimestamp() time.Time { return blockTimestamp(n.BlockTime) }
+
 type NewPairNotification struct {
-	Slot      uint64 `json:"slot"`
-	Signature string `json:"signature"`
-	BlockTime uint64 `json:"blockTime"`
-	Pair      Pair   `json:"pair"`
+	Slot      uint64           `json:"slot"`
+	Signature solana.Signature `json:"signature"`
+	BlockTime uint64           `json:"blockTime"` // unix seconds, see BlockTimestamp
+	Pair      Pair             `json:"pair"`
 }
 
+func (n NewPairNotification) blockTime() uint64 { return n.BlockTime }
+
+// BlockTimestamp returns BlockTime as a time, the zero time if it is not set.
+func (n NewPairNotification) BlockTimestamp() time.Time { return blockTimestamp(n.BlockTime) }
+
 type SwapNotification struct {
-	Slot      uint64 `json:"slot"`
-	Signature string `json:"signature"`
-	BlockTime uint64 `json:"blockTime"`
-	Swap      Swap   `json:"swap"`
+	Slot      uint64           `json:"slot"`
+	Signature solana.Signature `json:"signature"`
+	BlockTime uint64           `json:"blockTime"` // unix seconds, see BlockTimestamp
+	Swap      Swap             `json:"swap"`
+}
+
+func (n SwapNotification) blockTime() uint64 { return n.BlockTime }
+
+// BlockTimestamp returns BlockTime as a time, the zero time if it is not set.
+func (n SwapNotification) BlockTimestamp() time.Time { return blockTimestamp(n.BlockTime) }
+
+// blockTimestamp converts a block time in unix seconds, zero meaning unknown.
+func blockTimestamp(blockTime uint64) time.Time {
+	if blockTime == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(blockTime), 0)
 }
 
 type Pair struct {
-	SourceExchange           string           `json:"sourceExchange"`           // The exchange where the pair is listed
+	SourceExchange           Exchange         `json:"sourceExchange"`           // The exchange where the pair is listed
 	AmmAccount               solana.PublicKey `json:"ammAccount"`               // The address of the AMM account for the pair, or the bonding curve for launch platforms.
 	BaseToken                Token            `json:"baseToken"`                // The base token in the pair
 	QuoteToken               Token            `json:"quoteToken"`               // The quote token in the pair, usually wrapped SOL
-	BaseTokenLiquidityAdded  string           `json:"baseTokenLiquidityAdded"`  // The amount of base token added to the liquidity pool
-	QuoteTokenLiquidityAdded string           `json:"quoteTokenLiquidityAdded"` // The amount of quote token added to the liquidity pool
-	Migration                string           `json:"migration,omitempty"`      // If this pair was the result of a launch migration, this field will contain the migration source exchange. e.g. "pumpfun" or "raydium_launchpad"
+	BaseTokenLiquidityAdded  Amount           `json:"baseTokenLiquidityAdded"`  // The amount of base token added to the liquidity pool
+	QuoteTokenLiquidityAdded Amount           `json:"quoteTokenLiquidityAdded"` // The amount of quote token added to the liquidity pool
+	Migration                Exchange         `json:"migration,omitempty"`      // If this pair was the result of a launch migration, this field will contain the migration source exchange. e.g. "pumpfun" or "raydium_launchpad"
 }
 
 type Token struct {
@@ -75,7 +121,7 @@ type Token struct {
 
 type TokenInfo struct {
 	Decimals        uint              `json:"decimals"`        // The number of decimal places for the token.
-	Supply          string            `json:"supply"`          // The total supply of the token, represented as a string to handle large numbers.
+	Supply          Amount            `json:"supply"`          // The total supply of the token in raw units, see SupplyUI.
 	MetaData        *TokenMetaData    `json:"metadata"`        // Metadata about the token, such as name, symbol, and logo.
 	MintAuthority   *solana.PublicKey `json:"mintAuthority"`   // The authority that can mint new tokens. (nil for non-mintable tokens)
 	FreezeAuthority *solana.PublicKey `json:"freezeAuthority"` // The authority that can freeze token accounts. (nil for non-freezable tokens)
@@ -94,14 +140,14 @@ type TokenSocials struct {
 }
 
 type Swap struct {
-	SourceExchange      string           `json:"sourceExchange"`      // The source exchange of the swap
+	SourceExchange      Exchange         `json:"sourceExchange"`      // The source exchange of the swap
 	AmmAccount          solana.PublicKey `json:"ammAccount"`          // The same as dexscreener pair address. This can also be the address of the bondin curve in launch token swaps like pump.fun and raydium launchpad.
 	BaseTokenMint       solana.PublicKey `json:"baseTokenMint"`       // The token being traded
 	QuoteTokenMint      solana.PublicKey `json:"quoteTokenMint"`      // The token being traded for and what the price is quoted in. Note: this is usually wrapped sol.
 	WalletAccount       solana.PublicKey `json:"walletAccount"`       // The wallet in the swap thats not the pool.
-	QuotePrice          *big.Float       `json:"quotePrice"`          // The execution price of the swap. This field is calculated as the quoteAmount divided by the baseAmount.
+	QuotePrice          Decimal          `json:"quotePrice"`          // The execution price of the swap. This field is calculated as the quoteAmount divided by the baseAmount, see QuoteAmount.
 	USDValue            *float64         `json:"usdValue"`            // The value of the swap in USD.
-	BaseAmount          string           `json:"baseAmount"`          // The amount of base token traded in the swap
-	SwapType            string           `json:"swapType"`            // The type of swap (buy/sell)
-	QuoteTokenLiquidity string           `json:"quoteTokenLiquidity"` // (Beta Testing) The amount of quote token in the liquidity pool. This is not always available and could be an empty string.
+	BaseAmount          Amount           `json:"baseAmount"`          // The amount of base token traded in the swap
+	SwapType            SwapType         `json:"swapType"`            // The type of swap (buy/sell)
+	QuoteTokenLiquidity Amount           `json:"quoteTokenLiquidity"` // (Beta Testing) The amount of quote token in the liquidity pool. This is not always available and could be unset.
 }
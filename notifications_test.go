@@ -0,0 +1,44 @@
+package solanastreaming_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+func TestNotificationSignature(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	sub, err := cli.SubscribeSwaps(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	signature := solana.MustSignatureFromBase58("5VERv8NMvzbJMEkV8xnrLkEaWRtSz9CosKDYjCJjBRnbJLgp8uirBgmQpjKhoR4tjF3ZpRzrFmBV6UjKdiSZkQUW")
+	blockTime := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	srv.PushSwap(solanastreaming.SwapNotification{Slot: 1, Signature: signature, BlockTime: uint64(blockTime.Unix())})
+	srv.PushRaw([]byte(fmt.Sprintf(`{"id":0,"subscription_id":%d,"method":"swapNotification","params":{"slot":2,"signature":"not-base58"}}`, sub.ID())))
+
+	ev, err := sub.Receive(ctx)
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+	if ev.Signature != signature || !ev.BlockTimestamp().Equal(blockTime) {
+		t.Fatalf("unexpected notification: %s %s", ev.Signature, ev.BlockTimestamp())
+	}
+	if _, err := sub.Receive(ctx); err == nil {
+		t.Fatal("expected a decode error for the malformed signature")
+	}
+	if stats := sub.Stats(); stats.DecodeErrors != 1 {
+		t.Fatalf("decode error not counted: %+v", stats)
+	}
+	if !(solanastreaming.NewPairNotification{}).BlockTimestamp().IsZero() {
+		t.Fatal("missing block time is not the zero time")
+	}
+}
@@ -0,0 +1,164 @@
+package solanastreaming
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Option configures a Client, see New.
+type Option func(*Client)
+
+const (
+	defaultHost           = "wss://api.solanastreaming.com"
+	defaultUserAgent      = "solanastreaming-client-go"
+	defaultRequestTimeout = 5 * time.Second
+)
+
+// WithHost sets the websocket url to connect to.
+func WithHost(host string) Option {
+	return WithEndpoints(Endpoint{URL: host})
+}
+
+// WithEndpoints sets the endpoints to connect to, see SetEndpoints.
+func WithEndpoints(endpoints ...Endpoint) Option {
+	return func(c *Client) {
+		c.endpoints = sortEndpoints(endpoints)
+	}
+}
+
+// WithLatencyProbe makes Connect call ProbeEndpoints first so the lowest latency endpoint is used.
+func WithLatencyProbe() Option {
+	return func(c *Client) {
+		c.probeEndpoints = true
+	}
+}
+
+// WithLogger sets the logger used by the client, e.g. slog.Default(). Every record is passed to it unless SetLogLevel is called.
+func WithLogger(logger Logger) Option {
+	return func(c *Client) {
+		c.log.set(logger)
+	}
+}
+
+// WithDialer sets the websocket dialer used to connect. The dialer is copied and other options such as
+// WithTLSConfig or WithProxy are applied on top of it.
+func WithDialer(dialer *websocket.Dialer) Option {
+	return func(c *Client) {
+		c.dialer = dialer
+	}
+}
+
+// WithTLSConfig sets the TLS config used for wss connections.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = config
+	}
+}
+
+// WithProxy connects through the given proxy. http, https and socks5 proxy urls are supported.
+func WithProxy(proxyURL *url.URL) Option {
+	return func(c *Client) {
+		c.proxy = http.ProxyURL(proxyURL)
+	}
+}
+
+// WithProxyFunc sets a function that picks the proxy for each connection, e.g. http.ProxyFromEnvironment.
+func WithProxyFunc(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(c *Client) {
+		c.proxy = proxy
+	}
+}
+
+// WithHeader adds a header to the websocket handshake request. It can be used multiple times.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.headers.Add(key, value)
+	}
+}
+
+// WithHandshakeTimeout limits how long the websocket handshake can take. 0 or less keeps the dialer's timeout.
+func WithHandshakeTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.handshakeTimeout = timeout
+	}
+}
+
+// WithRequestTimeout limits how long subscribe, unsubscribe and update requests wait for a response. Defaults to 5 seconds,
+// 0 or less keeps the default.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		if timeout <= 0 {
+			timeout = defaultRequestTimeout
+		}
+		c.requestTimeout = timeout
+	}
+}
+
+// WithBufferSizes sets the websocket read and write buffer sizes in bytes. 0 or less keeps the default.
+func WithBufferSizes(read, write int) Option {
+	return func(c *Client) {
+		c.readBufferSize = read
+		c.writeBufferSize = write
+	}
+}
+
+// WithUserAgentSuffix appends suffix to the User-Agent sent in the handshake, e.g. "my-bot/1.2". "" keeps the default.
+func WithUserAgentSuffix(suffix string) Option {
+	return func(c *Client) {
+		c.userAgent = defaultUserAgent
+		if suffix = strings.TrimSpace(suffix); suffix != "" {
+			c.userAgent += " " + suffix
+		}
+	}
+}
+
+// WithReconnectPolicy sets the reconnect policy, see SetReconnectPolicy.
+func WithReconnectPolicy(policy ReconnectPolicy) Option {
+	return func(c *Client) {
+		c.reconnectPolicy = policy
+	}
+}
+
+// WithKeepalive sets the keepalive config, see SetKeepalive.
+func WithKeepalive(config KeepaliveConfig) Option {
+	return func(c *Client) {
+		c.keepalive = config
+	}
+}
+
+// newDialer builds the websocket dialer from the configured options.
+func (o *Client) newDialer() *websocket.Dialer {
+	dialer := *websocket.DefaultDialer
+	if o.dialer != nil {
+		dialer = *o.dialer
+	}
+	if o.tlsConfig != nil {
+		dialer.TLSClientConfig = o.tlsConfig
+	}
+	if o.proxy != nil {
+		dialer.Proxy = o.proxy
+	}
+	if o.handshakeTimeout > 0 {
+		dialer.HandshakeTimeout = o.handshakeTimeout
+	}
+	if o.readBufferSize > 0 {
+		dialer.ReadBufferSize = o.readBufferSize
+	}
+	if o.writeBufferSize > 0 {
+		dialer.WriteBufferSize = o.writeBufferSize
+	}
+	return &dialer
+}
+
+// handshakeHeader returns the headers sent with the websocket handshake.
+func (o *Client) handshakeHeader() http.Header {
+	header := o.headers.Clone()
+	header.Set("X-API-KEY", o.apiKey)
+	header.Set("User-Agent", o.userAgent)
+	return header
+}
@@ -0,0 +1,153 @@
+package solanastreaming
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestOptions(t *testing.T) {
+	proxyURL, _ := url.Parse("socks5://127.0.0.1:1080")
+	tlsConfig := &tls.Config{ServerName: "example.com"}
+	tests := []struct {
+		name  string
+		opts  []Option
+		check func(t *testing.T, c *Client, dialer *websocket.Dialer)
+	}{{
+		name: "defaults",
+		check: func(t *testing.T, c *Client, dialer *websocket.Dialer) {
+			if len(c.endpoints) != 1 || c.endpoints[0].URL != defaultHost {
+				t.Errorf("endpoints %v", c.endpoints)
+			}
+			if c.requestTimeout != defaultRequestTimeout || c.userAgent != defaultUserAgent || c.probeEndpoints {
+				t.Errorf("request timeout %s, user agent %q, probe %v", c.requestTimeout, c.userAgent, c.probeEndpoints)
+			}
+			if c.keepalive != DefaultKeepaliveConfig() || c.reconnectPolicy != DefaultReconnectPolicy() {
+				t.Errorf("keepalive %+v, reconnect policy %+v", c.keepalive, c.reconnectPolicy)
+			}
+			if dialer.HandshakeTimeout != websocket.DefaultDialer.HandshakeTimeout || dialer.TLSClientConfig != nil {
+				t.Errorf("dialer %+v", dialer)
+			}
+			header := c.handshakeHeader()
+			if header.Get("X-API-KEY") != "key" || header.Get("User-Agent") != defaultUserAgent {
+				t.Errorf("handshake header %v", header)
+			}
+		},
+	}, {
+		name: "endpoints sorted by weight",
+		opts: []Option{WithEndpoints(Endpoint{URL: "wss://a"}, Endpoint{URL: "wss://b", Weight: 2}, Endpoint{URL: "wss://c"}), WithLatencyProbe()},
+		check: func(t *testing.T, c *Client, dialer *websocket.Dialer) {
+			if len(c.endpoints) != 3 || c.endpoints[0].URL != "wss://b" || c.endpoints[1].URL != "wss://a" || !c.probeEndpoints {
+				t.Errorf("endpoints %v, probe %v", c.endpoints, c.probeEndpoints)
+			}
+		},
+	}, {
+		name: "host",
+		opts: []Option{WithHost("wss://example.com")},
+		check: func(t *testing.T, c *Client, dialer *websocket.Dialer) {
+			if len(c.endpoints) != 1 || c.endpoints[0].URL != "wss://example.com" {
+				t.Errorf("endpoints %v", c.endpoints)
+			}
+		},
+	}, {
+		name: "dialer with overrides",
+		opts: []Option{
+			WithDialer(&websocket.Dialer{HandshakeTimeout: time.Minute, EnableCompression: true}),
+			WithTLSConfig(tlsConfig),
+			WithProxy(proxyURL),
+			WithHandshakeTimeout(3 * time.Second),
+			WithBufferSizes(8192, 4096),
+		},
+		check: func(t *testing.T, c *Client, dialer *websocket.Dialer) {
+			if !dialer.EnableCompression || dialer.HandshakeTimeout != 3*time.Second || dialer.TLSClientConfig != tlsConfig {
+				t.Errorf("dialer %+v", dialer)
+			}
+			if dialer.ReadBufferSize != 8192 || dialer.WriteBufferSize != 4096 {
+				t.Errorf("buffer sizes %d %d", dialer.ReadBufferSize, dialer.WriteBufferSize)
+			}
+			if proxy, err := dialer.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "api"}}); err != nil || proxy.String() != proxyURL.String() {
+				t.Errorf("proxy %v %v", proxy, err)
+			}
+		},
+	}, {
+		name: "dialer is copied",
+		opts: []Option{WithDialer(websocket.DefaultDialer), WithHandshakeTimeout(time.Second)},
+		check: func(t *testing.T, c *Client, dialer *websocket.Dialer) {
+			if dialer == websocket.DefaultDialer || websocket.DefaultDialer.HandshakeTimeout == time.Second {
+				t.Error("the dialer passed in was modified")
+			}
+		},
+	}, {
+		name: "proxy func",
+		opts: []Option{WithProxyFunc(http.ProxyURL(proxyURL))},
+		check: func(t *testing.T, c *Client, dialer *websocket.Dialer) {
+			if proxy, err := dialer.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "api"}}); err != nil || proxy.String() != proxyURL.String() {
+				t.Errorf("proxy %v %v", proxy, err)
+			}
+		},
+	}, {
+		name: "headers and user agent",
+		opts: []Option{WithHeader("X-Trace", "1"), WithHeader("X-Trace", "2"), WithHeader("X-API-KEY", "other"), WithUserAgentSuffix("my-bot/1.2")},
+		check: func(t *testing.T, c *Client, dialer *websocket.Dialer) {
+			header := c.handshakeHeader()
+			if values := header.Values("X-Trace"); len(values) != 2 {
+				t.Errorf("repeated header %v", values)
+			}
+			if header.Get("X-API-KEY") != "key" {
+				t.Errorf("api key header overridden: %v", header)
+			}
+			if header.Get("User-Agent") != defaultUserAgent+" my-bot/1.2" {
+				t.Errorf("user agent %q", header.Get("User-Agent"))
+			}
+		},
+	}, {
+		name: "timeouts, keepalive and reconnect policy",
+		opts: []Option{
+			WithRequestTimeout(time.Minute),
+			WithKeepalive(KeepaliveConfig{PingInterval: time.Second}),
+			WithReconnectPolicy(ReconnectPolicy{}),
+		},
+		check: func(t *testing.T, c *Client, dialer *websocket.Dialer) {
+			if c.requestTimeout != time.Minute || c.keepalive != (KeepaliveConfig{PingInterval: time.Second}) || c.reconnectPolicy != (ReconnectPolicy{}) {
+				t.Errorf("request timeout %s, keepalive %+v, reconnect policy %+v", c.requestTimeout, c.keepalive, c.reconnectPolicy)
+			}
+		},
+	}, {
+		name: "invalid values keep the defaults",
+		opts: []Option{
+			WithRequestTimeout(-time.Second),
+			WithHandshakeTimeout(-time.Second),
+			WithBufferSizes(-1, 0),
+			WithUserAgentSuffix("  "),
+			WithDialer(nil),
+			WithLogger(nil),
+		},
+		check: func(t *testing.T, c *Client, dialer *websocket.Dialer) {
+			if c.requestTimeout != defaultRequestTimeout || c.userAgent != defaultUserAgent {
+				t.Errorf("request timeout %s, user agent %q", c.requestTimeout, c.userAgent)
+			}
+			if dialer.HandshakeTimeout != websocket.DefaultDialer.HandshakeTimeout || dialer.ReadBufferSize != 0 || dialer.WriteBufferSize != 0 {
+				t.Errorf("dialer %+v", dialer)
+			}
+			c.log.Error("logged to the nop logger")
+		},
+	}, {
+		name: "later options win",
+		opts: []Option{WithRequestTimeout(time.Minute), WithRequestTimeout(time.Second), WithHost("wss://a"), WithHost("wss://b")},
+		check: func(t *testing.T, c *Client, dialer *websocket.Dialer) {
+			if c.requestTimeout != time.Second || len(c.endpoints) != 1 || c.endpoints[0].URL != "wss://b" {
+				t.Errorf("request timeout %s, endpoints %v", c.requestTimeout, c.endpoints)
+			}
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := New("key", test.opts...)
+			test.check(t, c, c.newDialer())
+		})
+	}
+}
@@ -3,6 +3,7 @@ package solanastreaming
 import (
 	"context"
 	"encoding/json"
+	"iter"
 )
 
 type NewPairSubscribeParams struct {
@@ -17,7 +18,7 @@ type NewPairsSubscription struct {
 	sub subscription[NewPairNotification]
 }
 
-func (c *Client) SubscribeNewPairs(ctx context.Context, params *NewPairSubscribeParams) (*NewPairsSubscription, error) {
+func (c *Client) SubscribeNewPairs(ctx context.Context, params *NewPairSubscribeParams, opts ...SubscriptionOption) (*NewPairsSubscription, error) {
 
 	var input *json.RawMessage
 	if params != nil {
@@ -28,18 +29,16 @@ func (c *Client) SubscribeNewPairs(ctx context.Context, params *NewPairSubscribe
 		input = (*json.RawMessage)(&data)
 	}
 
-	subscriptionID, receiver, err := c.subscribe(ctx, "newPairSubscribe", input)
+	active, err := c.subscribe(ctx, "newPairSubscribe", input, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return &NewPairsSubscription{
-		sub: subscription[NewPairNotification]{
-			ID:       subscriptionID,
-			messages: receiver,
-			client:   c,
-		},
-	}, nil
+	sub := &NewPairsSubscription{
+		sub: newSubscription[NewPairNotification](c, active),
+	}
+	c.track(active, sub)
+	return sub, nil
 }
 
 func (s *NewPairsSubscription) Receive(ctx context.Context) (NewPairNotification, error) {
@@ -49,12 +48,87 @@ func (s *NewPairsSubscription) Receive(ctx context.Context) (NewPairNotification
 	return receive[NewPairNotification](ctx, s.sub)
 }
 
-// Unsubscribe from the new pair notifications. To prevent deadlocks, Avoid putting your Unsubscribe() call in your Receive() loop
+// ReceiveWithInfo is Receive that also reports the params generation and arrival time of the notification.
+func (s *NewPairsSubscription) ReceiveWithInfo(ctx context.Context) (NewPairNotification, NotificationInfo, error) {
+	if s == nil {
+		return NewPairNotification{}, NotificationInfo{}, ErrNoSubscription
+	}
+	return receiveWithInfo[NewPairNotification](ctx, s.sub)
+}
+
+// All returns an iterator over the notifications, for use with range. It stops when ctx is done, after Unsubscribe
+// or after yielding the error that ended the connection.
+func (s *NewPairsSubscription) All(ctx context.Context) iter.Seq2[NewPairNotification, error] {
+	if s == nil {
+		return func(yield func(NewPairNotification, error) bool) {
+			yield(NewPairNotification{}, ErrNoSubscription)
+		}
+	}
+	return all(ctx, s.sub)
+}
+
+// Chan returns a channel of notifications that is closed when the subscription ends. Use Errors or Err for errors.
+// Chan, Errors and OnNewPair share the same stream and should not be combined with Receive or All.
+func (s *NewPairsSubscription) Chan() <-chan NewPairNotification {
+	s.sub.delivery.start(s.sub)
+	return s.sub.delivery.ch
+}
+
+// Errors returns a channel of errors that is closed together with Chan. Errors are dropped if nobody reads them.
+func (s *NewPairsSubscription) Errors() <-chan error {
+	s.sub.delivery.start(s.sub)
+	return s.sub.delivery.errs
+}
+
+// Err returns the error that closed Chan, or nil if it was closed by Unsubscribe.
+func (s *NewPairsSubscription) Err() error {
+	return s.sub.delivery.Err()
+}
+
+// OnNewPair calls handler for every new pair notification until the subscription ends.
+// Pairs with the same amm account are handled in order unless WithUnordered is set.
+func (s *NewPairsSubscription) OnNewPair(handler func(NewPairNotification), opts ...HandlerOption) {
+	s.sub.delivery.start(s.sub)
+	handle(s.sub.delivery, handler, func(n NewPairNotification) uint64 { return accountKey(n.Pair.AmmAccount) }, opts)
+}
+
+// ID returns the server side subscription id, see Subscription.
+func (s *NewPairsSubscription) ID() uint {
+	return s.sub.ID()
+}
+
+// Method returns "newPairSubscribe".
+func (s *NewPairsSubscription) Method() string {
+	return s.sub.Method()
+}
+
+// Params returns the current subscription params.
+func (s *NewPairsSubscription) Params() json.RawMessage {
+	return s.sub.Params()
+}
+
+// Stats returns the subscription counters.
+func (s *NewPairsSubscription) Stats() SubscriptionStats {
+	return s.sub.Stats()
+}
+
+// Done is closed when the subscription ends.
+func (s *NewPairsSubscription) Done() <-chan struct{} {
+	return s.sub.Done()
+}
+
+// Dropped returns how many notifications were discarded by the overflow policy.
+func (s *NewPairsSubscription) Dropped() uint64 {
+	return s.Stats().Dropped
+}
+
+// Unsubscribe from the new pair notifications. It is safe to call from any goroutine, including a Receive loop.
 func (s *NewPairsSubscription) Unsubscribe(ctx context.Context) error {
 	return unsubscribe[NewPairNotification](ctx, s.sub, "newPairUnsubscribe")
 }
 
-// UpdateParams change the subscription parameters. To prevent deadlocks, Avoid putting your UpdateParams() call in your Receive() loop
+// UpdateParams change the subscription parameters. It is safe to call from any goroutine, including a Receive loop.
+// Notifications sent under the old params are reported as stale by ReceiveWithInfo, or skipped with WithDropStale.
 func (s *NewPairsSubscription) UpdateParams(ctx context.Context, params *NewPairSubscribeParams) error {
 	data, err := json.Marshal(params)
 	if err != nil {
@@ -62,3 +136,8 @@ func (s *NewPairsSubscription) UpdateParams(ctx context.Context, params *NewPair
 	}
 	return updateParams[NewPairNotification](ctx, s.sub, (*json.RawMessage)(&data))
 }
+
+// Generation returns the params generation, incremented by every successful UpdateParams.
+func (s *NewPairsSubscription) Generation() uint64 {
+	return s.sub.active.generation.Load()
+}
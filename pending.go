@@ -0,0 +1,93 @@
+package solanastreaming
+
+import (
+	"time"
+)
+
+const (
+	// pendingTTL is how long notifications for an unknown subscription id are kept for a subscribe call to claim them.
+	pendingTTL = 10 * time.Second
+	// maxPendingPerSubscription bounds the notifications held for a single unknown subscription id.
+	maxPendingPerSubscription = 1000
+)
+
+// pendingNotifications holds notifications that arrived before their subscription was registered. The server can
+// start sending notifications before the subscribe response has been processed.
+type pendingNotifications struct {
+	firstSeen time.Time
+	messages  []*wireMessage
+}
+
+// UnclaimedNotifications returns how many notifications were discarded because no subscription claimed them.
+func (o *Client) UnclaimedNotifications() uint64 {
+	return o.unclaimed.Load()
+}
+
+// hold keeps a notification for an unknown subscription id. Must be called with lock held.
+func (o *Client) hold(event *wireMessage) {
+	o.expirePending(time.Now())
+	pending := o.pending[event.SubscriptionID]
+	if pending == nil {
+		pending = &pendingNotifications{firstSeen: time.Now()}
+		o.pending[event.SubscriptionID] = pending
+		// expire them even if no other frame arrives
+		time.AfterFunc(pendingTTL, func() {
+			o.lock.Lock()
+			defer o.lock.Unlock()
+			o.expirePending(time.Now())
+		})
+	}
+	if len(pending.messages) >= maxPendingPerSubscription {
+		o.unclaimed.Add(1)
+		event.release()
+		return
+	}
+	pending.messages = append(pending.messages, event)
+}
+
+// register maps a subscription id to a subscription and hands it the notifications held for that id according to
+// its overflow policy. It reports whether they overflowed under OverflowDisconnect, the caller then has to call
+// slowConsumer once lock is released. Must be called with lock held.
+func (o *Client) register(subscriptionID uint, a *activeSubscription) (overflowed bool) {
+	o.streams[subscriptionID] = a
+	pending := o.pending[subscriptionID]
+	if pending == nil {
+		return false
+	}
+	delete(o.pending, subscriptionID)
+
+	// the read loop only delivers once register returns so these go first
+	for _, message := range pending.messages {
+		overflowed = a.offer(message) || overflowed
+	}
+	return overflowed
+}
+
+// expirePending discards held notifications nobody claimed in time. Must be called with lock held.
+func (o *Client) expirePending(now time.Time) {
+	for subscriptionID, pending := range o.pending {
+		if now.Sub(pending.firstSeen) < pendingTTL {
+			continue
+		}
+		o.discardPending(subscriptionID, pending)
+	}
+}
+
+// dropPending discards every held notification. Subscription ids are per connection, so notifications held on a
+// previous connection must not go to a subscription that gets the same id on the next one. Must be called with lock
+// held.
+func (o *Client) dropPending() {
+	for subscriptionID, pending := range o.pending {
+		o.discardPending(subscriptionID, pending)
+	}
+}
+
+// discardPending counts, logs and releases the notifications held for subscriptionID. Must be called with lock held.
+func (o *Client) discardPending(subscriptionID uint, pending *pendingNotifications) {
+	delete(o.pending, subscriptionID)
+	o.unclaimed.Add(uint64(len(pending.messages)))
+	o.log.Warn("wss unclaimed notifications", LogKeySubscriptionID, subscriptionID, "count", len(pending.messages))
+	for _, message := range pending.messages {
+		message.release()
+	}
+}
@@ -0,0 +1,34 @@
+package solanastreaming_test
+
+import (
+	"testing"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+func TestPendingDroppedOnReconnect(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	// held for a subscription id nobody claims, the subscribe response comes after it on the same connection
+	srv.PushRaw([]byte(`{"id":0,"subscription_id":99,"method":"swapNotification","params":{"slot":1}}`))
+	if _, err := cli.SubscribeLatestBlock(ctx); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	if cli.UnclaimedNotifications() != 0 {
+		t.Fatalf("unexpected unclaimed notifications: %d", cli.UnclaimedNotifications())
+	}
+
+	// the id means nothing on the next connection
+	srv.Disconnect()
+	if err := srv.WaitFor(ctx, func() bool { return len(srv.Calls("latestBlockSubscribe")) == 2 }); err != nil {
+		t.Fatalf("subscription not replayed: %v", err)
+	}
+	waitUntil(t, ctx, func() bool { return cli.UnclaimedNotifications() == 1 })
+	if stats := cli.Stats(); stats.Unclaimed != 1 || stats.State != solanastreaming.StateConnected {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
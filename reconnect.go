@@ -0,0 +1,150 @@
+package solanastreaming
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ReconnectPolicy controls how the client reconnects after the connection drops.
+// Active subscriptions are replayed with their latest params once the connection is back.
+type ReconnectPolicy struct {
+	Enabled        bool
+	InitialBackoff time.Duration // delay before the first reconnect attempt
+	MaxBackoff     time.Duration // upper bound for the delay between attempts
+	Multiplier     float64       // growth factor applied to the delay after every failed attempt
+	Jitter         float64       // fraction of the delay that is randomised, between 0 and 1
+	MaxAttempts    int           // give up after this many failed attempts, 0 retries forever
+}
+
+// DefaultReconnectPolicy returns the policy used by New.
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		Enabled:        true,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// backoff returns the delay before the given (zero based) reconnect attempt.
+func (p ReconnectPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	for i := 0; i < attempt && delay < float64(p.MaxBackoff); i++ {
+		delay *= max(p.Multiplier, 1)
+	}
+	if p.MaxBackoff > 0 {
+		delay = min(delay, float64(p.MaxBackoff))
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(max(delay, 0))
+}
+
+// reconnect is called from the read loop once the connection has dropped. It retries with backoff
+// until a new connection is established or the policy gives up, in which case cause becomes final.
+func (o *Client) reconnect(cause error) {
+	o.stateLock.Lock()
+	policy := o.reconnectPolicy
+	closed := o.closed
+	dead := o.dead
+	o.stateLock.Unlock()
+
+	disconnectedAt := time.Now()
+	if closed {
+		// Close or Shutdown record the final error themselves
+		return
+	}
+	o.emitDisconnect(DisconnectEvent{Err: cause, Time: disconnectedAt})
+	if !policy.Enabled {
+		o.fail(cause)
+		o.setState(StateFailed, cause)
+		return
+	}
+	o.setState(StateReconnecting, cause)
+
+	dropCause := cause
+	for attempt := 0; policy.MaxAttempts == 0 || attempt < policy.MaxAttempts; attempt++ {
+		delay := policy.backoff(attempt)
+		o.log.Info("wss reconnect", "attempt", attempt+1, "delay", delay)
+		select {
+		case <-dead:
+			return
+		case <-time.After(delay):
+		}
+
+		conn, err := o.dial(context.Background(), true)
+		if err != nil {
+			cause = err
+			continue
+		}
+		o.stateLock.Lock()
+		closed = o.closed
+		o.stateLock.Unlock()
+		if closed {
+			// Close was called while dialing
+			conn.Close()
+			return
+		}
+		o.startReading(conn)
+		o.reconnects.Add(1)
+		o.setState(StateConnected, nil)
+		o.emitReconnect(ReconnectEvent{
+			Attempt:        attempt + 1,
+			Err:            dropCause,
+			DisconnectedAt: disconnectedAt,
+			Time:           time.Now(),
+		})
+		o.resubscribe()
+		return
+	}
+	err := errors.Wrap(cause, "reconnect failed")
+	o.fail(err)
+	o.setState(StateFailed, err)
+}
+
+// resubscribe replays every active subscription on the current connection and remaps the new
+// subscription ids onto the existing subscriptions.
+func (o *Client) resubscribe() {
+	o.stateLock.Lock()
+	active := make([]*activeSubscription, 0, len(o.active))
+	for a := range o.active {
+		active = append(active, a)
+	}
+	o.stateLock.Unlock()
+
+	for _, a := range active {
+		o.lock.Lock()
+		method, params, previousID := a.method, a.params, a.id
+		o.lock.Unlock()
+
+		subscriptionID, err := o.requestSubscription(context.Background(), method, params)
+
+		o.lock.Lock()
+		// the old id means nothing on the new connection and could collide with another subscription
+		delete(o.streams, a.id)
+		overflowed := false
+		if err == nil {
+			a.id = subscriptionID
+			overflowed = o.register(subscriptionID, a)
+		}
+		o.lock.Unlock()
+		if overflowed {
+			o.slowConsumer(a)
+		}
+		if err != nil {
+			// left in the active set so the next reconnect tries again
+			o.log.Error("wss resubscribe failed", LogKeyMethod, method, LogKeySubscriptionID, previousID, LogKeyError, err)
+			o.emitResubscribeFailed(ResubscribeFailedEvent{
+				Method:         method,
+				SubscriptionID: previousID,
+				Err:            err,
+				Time:           time.Now(),
+			})
+		}
+	}
+}
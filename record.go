@@ -0,0 +1,101 @@
+package solanastreaming
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Frame directions in a recording.
+const (
+	FrameReceived = "in"  // frame read from the server
+	FrameSent     = "out" // frame written by the client
+)
+
+// RecordedFrame is one line of a recording.
+type RecordedFrame struct {
+	Time      time.Time `json:"t"`
+	Direction string    `json:"dir"`
+	Frame     string    `json:"frame"`
+}
+
+// Recorder writes every raw websocket frame with its timestamp to a gzip compressed JSONL capture.
+// Sent frames are recorded too so a Replayer can answer the client's requests. Attach it with WithRecorder.
+type Recorder struct {
+	lock sync.Mutex
+	gz   *gzip.Writer
+	enc  *json.Encoder
+	err  error
+}
+
+// NewRecorder creates a recorder writing to w. Close must be called to flush the capture.
+func NewRecorder(w io.Writer) *Recorder {
+	gz := gzip.NewWriter(w)
+	return &Recorder{
+		gz:  gz,
+		enc: json.NewEncoder(gz),
+	}
+}
+
+// WithRecorder records every frame sent and received by the client.
+func WithRecorder(recorder *Recorder) Option {
+	return func(c *Client) {
+		c.recorder.Store(recorder)
+	}
+}
+
+// SetRecorder starts recording to recorder, or stops recording if it is nil.
+func (o *Client) SetRecorder(recorder *Recorder) {
+	o.recorder.Store(recorder)
+}
+
+// Record appends a frame to the capture. Write errors are kept and returned by Close.
+func (r *Recorder) Record(direction string, frame []byte, at time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.err = r.enc.Encode(RecordedFrame{Time: at, Direction: direction, Frame: string(frame)})
+}
+
+// Close flushes the capture. It does not close the underlying writer.
+func (r *Recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if err := r.gz.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// record passes a frame to the recorder if one is attached.
+func (o *Client) record(direction string, frame []byte) {
+	if recorder := o.recorder.Load(); recorder != nil {
+		recorder.Record(direction, frame, time.Now())
+	}
+}
+
+// ReadRecording reads every frame of a capture written by a Recorder.
+func ReadRecording(r io.Reader) ([]RecordedFrame, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var frames []RecordedFrame
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		var frame RecordedFrame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, scanner.Err()
+}
@@ -0,0 +1,87 @@
+package solanastreaming_test
+
+import (
+	"bytes"
+	"testing"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+func TestRecordReplay(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+
+	// record a live session
+	var capture bytes.Buffer
+	recorder := solanastreaming.NewRecorder(&capture)
+	cli := newTestClient(t, srv)
+	cli.SetRecorder(recorder)
+	sub, err := cli.SubscribeLatestBlock(ctx)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	for block := uint64(1); block <= 5; block++ {
+		srv.PushLatestBlock(solanastreaming.LatestBlockNotification{Block: block})
+		if _, err := sub.Receive(ctx); err != nil {
+			t.Fatalf("receive error: %v", err)
+		}
+	}
+	cli.Close()
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("failed to close recorder: %v", err)
+	}
+
+	frames, err := solanastreaming.ReadRecording(&capture)
+	if err != nil {
+		t.Fatalf("failed to read recording: %v", err)
+	}
+
+	t.Run("server", func(t *testing.T) {
+		replay, err := solanastreaming.NewReplayServer(frames, solanastreaming.ReplayOptions{})
+		if err != nil {
+			t.Fatalf("failed to start replay: %v", err)
+		}
+		defer replay.Close()
+		expectReplay(t, solanastreaming.New("", solanastreaming.WithHost(replay.URL())), replay.Done())
+	})
+	t.Run("sessions", func(t *testing.T) {
+		// every connection gets the whole recording
+		replay, err := solanastreaming.NewReplayServer(frames, solanastreaming.ReplayOptions{Speed: 100})
+		if err != nil {
+			t.Fatalf("failed to start replay: %v", err)
+		}
+		t.Cleanup(func() { replay.Close() })
+		for _, name := range []string{"first", "second"} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				expectReplay(t, solanastreaming.New("", solanastreaming.WithHost(replay.URL())), replay.Done())
+			})
+		}
+	})
+	t.Run("transport", func(t *testing.T) {
+		replay := solanastreaming.NewReplayTransport(frames, solanastreaming.ReplayOptions{Speed: 100})
+		expectReplay(t, solanastreaming.New("", solanastreaming.WithTransport(replay)), replay.Done())
+	})
+}
+
+// expectReplay checks the recorded blocks are played back to cli.
+func expectReplay(t *testing.T, cli *solanastreaming.Client, done <-chan struct{}) {
+	ctx := testContext(t)
+	if err := cli.Connect(ctx); err != nil {
+		t.Fatalf("failed to connect to replay: %v", err)
+	}
+	defer cli.Close()
+	sub, err := cli.SubscribeLatestBlock(ctx)
+	if err != nil {
+		t.Fatalf("failed to subscribe to replay: %v", err)
+	}
+	for block := uint64(1); block <= 5; block++ {
+		ev, err := sub.Receive(ctx)
+		if err != nil || ev.Block != block {
+			t.Fatalf("expected block %d, got %#v %v", block, ev, err)
+		}
+	}
+	<-done
+}
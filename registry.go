@@ -0,0 +1,96 @@
+package solanastreaming
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+)
+
+// Subscription is implemented by every subscription type.
+type Subscription interface {
+	// ID returns the server side subscription id. It changes when the subscription is replayed after a reconnect.
+	ID() uint
+	// Method returns the subscribe method, e.g. "swapSubscribe".
+	Method() string
+	// Params returns the current subscription params as sent to the server, nil if there are none.
+	Params() json.RawMessage
+	Unsubscribe(ctx context.Context) error
+	Stats() SubscriptionStats
+	// Done is closed when the subscription ends.
+	Done() <-chan struct{}
+}
+
+// SubscriptionStats is a snapshot of the counters of a subscription.
+type SubscriptionStats struct {
+	Received         uint64       // notifications received from the server
+	Bytes            uint64       // size of the received notification frames
+	DecodeErrors     uint64       // notifications that could not be decoded
+	Dropped          uint64       // notifications discarded by the overflow policy
+	Buffered         int          // notifications waiting to be read
+	Stale            uint64       // notifications skipped by WithDropStale
+	LastNotification time.Time    // when the last notification was received, zero if none was
+	Latency          LatencyStats // time from the block time of a notification to its receipt, recorded when it is read
+}
+
+var (
+	_ Subscription = (*SwapsSubscription)(nil)
+	_ Subscription = (*NewPairsSubscription)(nil)
+	_ Subscription = (*LatestBlockSubscription)(nil)
+)
+
+// Subscriptions returns the active subscriptions ordered by id.
+func (o *Client) Subscriptions() []Subscription {
+	o.stateLock.Lock()
+	subscriptions := make([]Subscription, 0, len(o.active))
+	for a := range o.active {
+		if a.handle != nil {
+			subscriptions = append(subscriptions, a.handle)
+		}
+	}
+	o.stateLock.Unlock()
+
+	slices.SortFunc(subscriptions, func(a, b Subscription) int {
+		return cmp.Compare(a.ID(), b.ID())
+	})
+	return subscriptions
+}
+
+// UnsubscribeAll unsubscribes every active subscription.
+func (o *Client) UnsubscribeAll(ctx context.Context) error {
+	var errs []error
+	for _, sub := range o.Subscriptions() {
+		errs = append(errs, sub.Unsubscribe(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// track makes handle visible in Subscriptions.
+func (o *Client) track(a *activeSubscription, handle Subscription) {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	a.handle = handle
+}
+
+func (s subscription[T]) Method() string {
+	return s.active.method
+}
+
+func (s subscription[T]) Params() json.RawMessage {
+	s.client.lock.Lock()
+	defer s.client.lock.Unlock()
+	if s.active.params == nil {
+		return nil
+	}
+	return slices.Clone(*s.active.params)
+}
+
+func (s subscription[T]) Stats() SubscriptionStats {
+	return s.active.snapshot()
+}
+
+func (s subscription[T]) Done() <-chan struct{} {
+	return s.active.done
+}
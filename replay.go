@@ -0,0 +1,312 @@
+package solanastreaming
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ReplayOptions controls how a recording is played back.
+type ReplayOptions struct {
+	Speed float64 // playback speed, 1 is the original pace, 2 twice as fast, 0 as fast as possible
+}
+
+// Replayer plays back a recording made by a Recorder as if it were the live server. Subscribe requests are answered
+// with the recorded responses so the recorded notifications are routed to the client's subscriptions. Every
+// connection plays the recording from the start once it sends its first subscribe request, so several clients can
+// replay it at the same time.
+type Replayer struct {
+	options       ReplayOptions
+	subscriptions map[string][]json.RawMessage // recorded subscribe results by method, in order
+	notifications []RecordedFrame              // received frames that are not responses
+
+	doneOnce sync.Once
+	done     chan struct{}
+}
+
+// NewReplayer prepares a recording for playback, see ReadRecording.
+func NewReplayer(frames []RecordedFrame, options ReplayOptions) *Replayer {
+	r := &Replayer{
+		options:       options,
+		subscriptions: make(map[string][]json.RawMessage),
+		done:          make(chan struct{}),
+	}
+	var header struct {
+		ID     int             `json:"id"`
+		Method string          `json:"method"`
+		Result json.RawMessage `json:"result"`
+	}
+	requests := make(map[int]string) // method by request id
+	for _, frame := range frames {
+		header.ID, header.Method, header.Result = 0, "", nil
+		if json.Unmarshal([]byte(frame.Frame), &header) != nil {
+			continue
+		}
+		switch {
+		case frame.Direction == FrameSent:
+			requests[header.ID] = header.Method
+		case header.ID == 0:
+			r.notifications = append(r.notifications, frame)
+		case unsubscribeMethods[requests[header.ID]] != "" && header.Result != nil:
+			method := requests[header.ID]
+			r.subscriptions[method] = append(r.subscriptions[method], header.Result)
+		}
+	}
+	return r
+}
+
+// Done is closed once a connection has played every recorded notification.
+func (r *Replayer) Done() <-chan struct{} {
+	return r.done
+}
+
+// replaySession answers the requests of a single connection and plays the recording to it.
+type replaySession struct {
+	replayer   *Replayer
+	subscribed map[string]int // recorded subscriptions handed out per method
+	startOnce  sync.Once
+	started    chan struct{}
+}
+
+func (r *Replayer) newSession() *replaySession {
+	return &replaySession{
+		replayer:   r,
+		subscribed: make(map[string]int),
+		started:    make(chan struct{}),
+	}
+}
+
+// respond returns the response frame for a request frame written by the client.
+func (s *replaySession) respond(request []byte) []byte {
+	var header struct {
+		ID     int    `json:"id"`
+		Method string `json:"method"`
+	}
+	json.Unmarshal(request, &header)
+	response := map[string]any{"id": header.ID}
+
+	switch {
+	case unsubscribeMethods[header.Method] != "":
+		results := s.replayer.subscriptions[header.Method]
+		n := s.subscribed[header.Method]
+		if n >= len(results) {
+			response["error"] = map[string]any{"code": -32602, "message": "subscription not in recording"}
+			break
+		}
+		s.subscribed[header.Method]++
+		response["result"] = results[n]
+		s.startOnce.Do(func() { close(s.started) })
+	case header.Method == "startSimulation":
+		return nil
+	default:
+		response["result"] = map[string]any{"message": "ok"}
+	}
+	data, _ := json.Marshal(response)
+	return data
+}
+
+// play writes the notifications at the configured pace until the recording ends, write fails or ctx is done.
+func (s *replaySession) play(ctx context.Context, write func([]byte) error) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-s.started:
+	}
+	r := s.replayer
+	var last time.Time // recorded time of the last notification played
+	for _, frame := range r.notifications {
+		var wait time.Duration
+		if !last.IsZero() && r.options.Speed > 0 {
+			wait = time.Duration(float64(frame.Time.Sub(last)) / r.options.Speed)
+		}
+		if wait > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+		if write([]byte(frame.Frame)) != nil {
+			return
+		}
+		last = frame.Time
+	}
+	r.doneOnce.Do(func() { close(r.done) })
+}
+
+// ReplayServer serves a Replayer on a local websocket address, connect a client to it with WithHost(server.URL()).
+type ReplayServer struct {
+	*Replayer
+	listener net.Listener
+	server   *http.Server
+
+	connsLock sync.Mutex
+	conns     map[*websocket.Conn]struct{} // hijacked connections are not closed by server.Close
+}
+
+// NewReplayServer starts serving a recording on a local port.
+func NewReplayServer(frames []RecordedFrame, options ReplayOptions) (*ReplayServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &ReplayServer{
+		Replayer: NewReplayer(frames, options),
+		listener: listener,
+		conns:    make(map[*websocket.Conn]struct{}),
+	}
+	s.server = &http.Server{Handler: http.HandlerFunc(s.serve)}
+	go s.server.Serve(listener)
+	return s, nil
+}
+
+// URL returns the websocket url of the server.
+func (s *ReplayServer) URL() string {
+	return "ws://" + s.listener.Addr().String()
+}
+
+// Close stops the server and drops every connection.
+func (s *ReplayServer) Close() error {
+	err := s.server.Close()
+	s.connsLock.Lock()
+	defer s.connsLock.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	return err
+}
+
+func (s *ReplayServer) serve(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	s.connsLock.Lock()
+	s.conns[conn] = struct{}{}
+	s.connsLock.Unlock()
+	defer func() {
+		s.connsLock.Lock()
+		delete(s.conns, conn)
+		s.connsLock.Unlock()
+		conn.Close()
+	}()
+
+	var writeLock sync.Mutex
+	write := func(frame []byte) error {
+		writeLock.Lock()
+		defer writeLock.Unlock()
+		return conn.WriteMessage(websocket.TextMessage, frame)
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	session := s.newSession()
+	go session.play(ctx, write)
+	for {
+		_, request, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if response := session.respond(request); response != nil {
+			write(response)
+		}
+	}
+}
+
+// ReplayTransport plays a recording back in memory without a network listener, use it with WithTransport.
+// The url passed to Dial is ignored.
+type ReplayTransport struct {
+	*Replayer
+}
+
+// NewReplayTransport creates a transport that plays back a recording, see ReadRecording.
+func NewReplayTransport(frames []RecordedFrame, options ReplayOptions) *ReplayTransport {
+	return &ReplayTransport{Replayer: NewReplayer(frames, options)}
+}
+
+func (t *ReplayTransport) Dial(_ context.Context, _ string, _ http.Header) (Conn, error) {
+	// the connection outlives the dial context
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &replayConn{
+		session: t.newSession(),
+		frames:  make(chan []byte, 1024),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	go c.session.play(ctx, c.push)
+	return c, nil
+}
+
+// replayConn is an in memory connection to a Replayer.
+type replayConn struct {
+	session *replaySession
+	frames  chan []byte // frames for ReadFrame
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	deadlineLock sync.Mutex
+	deadline     *time.Timer
+	expired      chan struct{}
+}
+
+func (c *replayConn) push(frame []byte) error {
+	select {
+	case <-c.ctx.Done():
+		return net.ErrClosed
+	case c.frames <- frame:
+		return nil
+	}
+}
+
+func (c *replayConn) ReadFrame() ([]byte, error) {
+	c.deadlineLock.Lock()
+	expired := c.expired
+	c.deadlineLock.Unlock()
+	select {
+	case <-c.ctx.Done():
+		return nil, net.ErrClosed
+	case <-expired:
+		return nil, os.ErrDeadlineExceeded
+	case frame := <-c.frames:
+		return frame, nil
+	}
+}
+
+func (c *replayConn) WriteFrame(frame []byte) error {
+	if response := c.session.respond(frame); response != nil {
+		return c.push(response)
+	}
+	return c.ctx.Err()
+}
+
+// Ping answers immediately, the recording has no network in between.
+func (c *replayConn) Ping(ctx context.Context) (time.Duration, error) {
+	return 0, c.ctx.Err()
+}
+
+func (c *replayConn) SetReadDeadline(t time.Time) error {
+	c.deadlineLock.Lock()
+	defer c.deadlineLock.Unlock()
+	if c.deadline != nil {
+		c.deadline.Stop()
+	}
+	c.deadline, c.expired = nil, nil
+	if t.IsZero() {
+		return nil
+	}
+	expired := make(chan struct{})
+	c.deadline, c.expired = time.AfterFunc(time.Until(t), func() { close(expired) }), expired
+	return nil
+}
+
+func (c *replayConn) Close() error {
+	c.cancel()
+	return nil
+}
@@ -0,0 +1,83 @@
+package solanastreaming
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Shutdown gracefully stops the client. It unsubscribes every subscription, sends a websocket close frame and waits
+// for consumers to drain notifications that are already buffered. Every subscription is then closed so Receive
+// returns ErrSubscriptionClosed. If ctx expires first the remaining steps are cut short and ctx.Err() is returned.
+func (o *Client) Shutdown(ctx context.Context) error {
+	o.stateLock.Lock()
+	o.closed = true
+	active := make([]*activeSubscription, 0, len(o.active))
+	for a := range o.active {
+		active = append(active, a)
+	}
+	o.stateLock.Unlock()
+
+	var errs []error
+	for _, a := range active {
+		o.lock.Lock()
+		unsubscribeMethod := unsubscribeMethods[a.method]
+		o.lock.Unlock()
+		errs = append(errs, o.requestUnsubscribe(ctx, unsubscribeMethod, a))
+	}
+
+	o.lock.Lock()
+	conn, readDone := o.conn, o.readDone
+	o.lock.Unlock()
+	if closer, ok := conn.(CloseWriter); ok {
+		// the server answers with its own close frame which ends the read loop
+		err := closer.WriteClose(time.Now().Add(time.Second))
+		if err == nil {
+			select {
+			case <-readDone:
+			case <-ctx.Done():
+			}
+		}
+	}
+
+	// wait for consumers to read what is already buffered
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for _, a := range active {
+		for a.buffered() > 0 && ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+			case <-ticker.C:
+			}
+		}
+		o.dropSubscription(a)
+	}
+
+	errs = append(errs, ctx.Err(), o.Close())
+	return errors.Join(errs...)
+}
+
+// unsubscribeMethods maps subscribe methods to the method that ends them.
+var unsubscribeMethods = map[string]string{
+	"swapSubscribe":        "swapUnsubscribe",
+	"newPairSubscribe":     "newPairUnsubscribe",
+	"latestBlockSubscribe": "latestBlockUnsubscribe",
+}
+
+// dropSubscription forgets a subscription locally and closes its channel so Receive returns ErrSubscriptionClosed
+// once the buffered notifications have been read.
+func (o *Client) dropSubscription(a *activeSubscription) {
+	o.stateLock.Lock()
+	_, ok := o.active[a]
+	delete(o.active, a)
+	o.stateLock.Unlock()
+	if !ok {
+		return
+	}
+
+	o.lock.Lock()
+	delete(o.streams, a.id)
+	o.lock.Unlock()
+
+	a.close()
+}
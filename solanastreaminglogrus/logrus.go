@@ -0,0 +1,64 @@
+// Package solanastreaminglogrus adapts a logrus logger to solanastreaming.Logger, for applications that still log
+// with logrus:
+//
+//	cli.SetLogger(solanastreaminglogrus.New(logrus.StandardLogger()))
+package solanastreaminglogrus
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/sirupsen/logrus"
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+)
+
+// badKey is used for a value without a key, as in log/slog.
+const badKey = "!BADKEY"
+
+type logger struct {
+	entry *logrus.Entry
+}
+
+// New returns a solanastreaming.Logger that logs to l, the key value pairs become logrus fields.
+func New(l logrus.FieldLogger) solanastreaming.Logger {
+	return logger{entry: l.WithFields(nil)}
+}
+
+func (l logger) Debug(msg string, args ...any) {
+	l.entry.WithFields(fields(args)).Debug(msg)
+}
+
+func (l logger) Info(msg string, args ...any) {
+	l.entry.WithFields(fields(args)).Info(msg)
+}
+
+func (l logger) Warn(msg string, args ...any) {
+	l.entry.WithFields(fields(args)).Warn(msg)
+}
+
+func (l logger) Error(msg string, args ...any) {
+	l.entry.WithFields(fields(args)).Error(msg)
+}
+
+// fields converts slog style arguments to logrus fields.
+func fields(args []any) logrus.Fields {
+	f := make(logrus.Fields, len(args)/2)
+	for len(args) > 0 {
+		switch key := args[0].(type) {
+		case slog.Attr:
+			f[key.Key] = key.Value.Any()
+			args = args[1:]
+		case string:
+			if len(args) == 1 {
+				f[badKey] = key
+				return f
+			}
+			f[key] = args[1]
+			args = args[2:]
+		default:
+			f[badKey] = fmt.Sprint(key)
+			args = args[1:]
+		}
+	}
+	return f
+}
@@ -0,0 +1,35 @@
+package solanastreaminglogrus_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreaminglogrus"
+)
+
+func TestLogger(t *testing.T) {
+	base, hook := test.NewNullLogger()
+	base.SetLevel(logrus.DebugLevel)
+	log := solanastreaminglogrus.New(base)
+
+	err := errors.New("broken pipe")
+	log.Error("wss write failed", "conn_id", uint64(3), "request_id", 42, slog.String("method", "swapSubscribe"), "err", err)
+	entry := hook.LastEntry()
+	if entry == nil || entry.Level != logrus.ErrorLevel || entry.Message != "wss write failed" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	want := logrus.Fields{"conn_id": uint64(3), "request_id": 42, "method": "swapSubscribe", "err": err}
+	for key, value := range want {
+		if entry.Data[key] != value {
+			t.Fatalf("field %s = %v, want %v", key, entry.Data[key], value)
+		}
+	}
+
+	log.Debug("odd", "dangling")
+	if got := hook.LastEntry().Data["!BADKEY"]; got != "dangling" {
+		t.Fatalf("unexpected bad key field: %v", got)
+	}
+}
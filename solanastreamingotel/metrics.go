@@ -0,0 +1,80 @@
+package solanastreamingotel
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+)
+
+// ObserveClient reports the statistics of client as asynchronous metrics until the registration is unregistered.
+// Notification metrics come from Client.MethodStats and carry the method attribute only, see MethodKey.
+func ObserveClient(client *solanastreaming.Client, opts ...Option) (metric.Registration, error) {
+	meter := newConfig(opts).meterProvider.Meter(scope)
+
+	var errs []error
+	counter := func(name, unit, description string) metric.Int64ObservableCounter {
+		c, err := meter.Int64ObservableCounter(name, metric.WithUnit(unit), metric.WithDescription(description))
+		errs = append(errs, err)
+		return c
+	}
+	gauge := func(name, unit, description string) metric.Float64ObservableGauge {
+		g, err := meter.Float64ObservableGauge(name, metric.WithUnit(unit), metric.WithDescription(description))
+		errs = append(errs, err)
+		return g
+	}
+
+	state := gauge("solanastreaming.connection.state", "1", "Connection state of the client, 1 for the current state.")
+	reconnects := counter("solanastreaming.reconnects", "{reconnect}", "Successful reconnects.")
+	requests := counter("solanastreaming.requests", "{request}", "Requests sent that wait for a response.")
+	requestTimeouts := counter("solanastreaming.request.timeouts", "{request}", "Requests that got no response within the request timeout.")
+	rtt := gauge("solanastreaming.ping.rtt", "s", "Last measured ping round trip.")
+	unclaimed := counter("solanastreaming.unclaimed_notifications", "{notification}", "Notifications discarded because no subscription claimed them.")
+
+	notifications := counter("solanastreaming.notifications", "{notification}", "Notifications received.")
+	bytes := counter("solanastreaming.notification.size", "By", "Size of the received notification frames.")
+	decodeErrors := counter("solanastreaming.decode_errors", "{notification}", "Notifications that could not be decoded.")
+	dropped := counter("solanastreaming.dropped", "{notification}", "Notifications discarded by the overflow policy.")
+	stale := counter("solanastreaming.stale", "{notification}", "Notifications skipped because they were sent under replaced params.")
+	buffered := gauge("solanastreaming.buffered", "{notification}", "Notifications waiting to be read.")
+	lagP50 := gauge("solanastreaming.block_time_lag.p50", "s", "Median time from the block time of a notification to its receipt.")
+	lagP99 := gauge("solanastreaming.block_time_lag.p99", "s", "99th percentile time from the block time of a notification to its receipt.")
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(func(_ context.Context, observer metric.Observer) error {
+		stats := client.Stats()
+		for _, s := range solanastreaming.ConnectionStates() {
+			value := 0.0
+			if s == stats.State {
+				value = 1
+			}
+			observer.ObserveFloat64(state, value, metric.WithAttributes(StateKey.String(s.String())))
+		}
+		observer.ObserveInt64(reconnects, int64(stats.Reconnects))
+		observer.ObserveInt64(requests, int64(stats.Requests))
+		observer.ObserveInt64(requestTimeouts, int64(stats.RequestTimeouts))
+		observer.ObserveFloat64(rtt, stats.RTT.Seconds())
+		observer.ObserveInt64(unclaimed, int64(stats.Unclaimed))
+
+		for method, stats := range client.MethodStats() {
+			attrs := metric.WithAttributeSet(attribute.NewSet(MethodKey.String(method)))
+			observer.ObserveInt64(notifications, int64(stats.Received), attrs)
+			observer.ObserveInt64(bytes, int64(stats.Bytes), attrs)
+			observer.ObserveInt64(decodeErrors, int64(stats.DecodeErrors), attrs)
+			observer.ObserveInt64(dropped, int64(stats.Dropped), attrs)
+			observer.ObserveInt64(stale, int64(stats.Stale), attrs)
+			observer.ObserveFloat64(buffered, float64(stats.Buffered), attrs)
+			if stats.Latency.Count > 0 {
+				observer.ObserveFloat64(lagP50, stats.Latency.Quantile(0.5).Seconds(), attrs)
+				observer.ObserveFloat64(lagP99, stats.Latency.Quantile(0.99).Seconds(), attrs)
+			}
+		}
+		return nil
+	}, state, reconnects, requests, requestTimeouts, rtt, unclaimed,
+		notifications, bytes, decodeErrors, dropped, stale, buffered, lagP50, lagP99)
+}
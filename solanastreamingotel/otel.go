@@ -0,0 +1,154 @@
+// Package solanastreamingotel instruments a SolanaStreaming client with OpenTelemetry. Instrumentation traces
+// client operations and records their duration, ObserveClient reports the client and subscription statistics as
+// asynchronous metrics. Both use the global providers unless WithTracerProvider or WithMeterProvider is passed.
+//
+//	inst := solanastreamingotel.NewInstrumentation()
+//	cli := solanastreaming.New(apiKey, solanastreaming.WithInstrumentation(inst))
+//	reg, err := solanastreamingotel.ObserveClient(cli)
+package solanastreamingotel
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+)
+
+const scope = "github.com/solanastreaming/solanastreaming-client-go/solanastreamingotel"
+
+// Attribute keys set on spans and metrics. The ids are only set on spans, metrics leave them out so the number of
+// series stays bounded.
+const (
+	OperationKey      = attribute.Key("solanastreaming.operation")
+	MethodKey         = attribute.Key("solanastreaming.method")
+	RequestIDKey      = attribute.Key("solanastreaming.request_id")
+	SubscriptionIDKey = attribute.Key("solanastreaming.subscription_id")
+	EndpointKey       = attribute.Key("solanastreaming.endpoint")
+	OutcomeKey        = attribute.Key("solanastreaming.outcome")
+	StateKey          = attribute.Key("solanastreaming.state")
+)
+
+// Option configures the instrumentation.
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+func newConfig(opts []Option) config {
+	c := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// WithTracerProvider sets the provider spans are created with.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the provider metrics are recorded with.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// Instrumentation implements solanastreaming.Instrumentation with spans and an operation duration histogram.
+type Instrumentation struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+}
+
+var _ solanastreaming.Instrumentation = (*Instrumentation)(nil)
+
+// NewInstrumentation creates the instrumentation, pass it to solanastreaming.WithInstrumentation.
+func NewInstrumentation(opts ...Option) *Instrumentation {
+	c := newConfig(opts)
+	meter := c.meterProvider.Meter(scope)
+	// the histogram falls back to a no-op if the meter can not create it
+	duration, _ := meter.Float64Histogram("solanastreaming.operation.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of client operations such as connect, subscribe and requests."),
+	)
+	return &Instrumentation{
+		tracer:   c.tracerProvider.Tracer(scope),
+		duration: duration,
+	}
+}
+
+// StartOperation starts a span for op as a child of the span in ctx.
+func (i *Instrumentation) StartOperation(ctx context.Context, op *solanastreaming.Operation) (context.Context, func(err error)) {
+	kind := trace.SpanKindInternal
+	if op.Name == solanastreaming.OperationRequest {
+		kind = trace.SpanKindClient
+	}
+	ctx, span := i.tracer.Start(ctx, "solanastreaming."+op.Name, trace.WithSpanKind(kind))
+	start := time.Now()
+	return ctx, func(err error) {
+		span.SetAttributes(operationAttributes(op, err)...)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		i.duration.Record(context.WithoutCancel(ctx), time.Since(start).Seconds(), metric.WithAttributes(metricAttributes(op, err)...))
+	}
+}
+
+// operationAttributes describes op once it ended.
+func operationAttributes(op *solanastreaming.Operation, err error) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{OperationKey.String(op.Name), OutcomeKey.String(outcome(err))}
+	if op.Method != "" {
+		attrs = append(attrs, MethodKey.String(op.Method))
+	}
+	if op.RequestID != 0 {
+		attrs = append(attrs, RequestIDKey.Int(op.RequestID))
+	}
+	if op.SubscriptionID != 0 {
+		attrs = append(attrs, SubscriptionIDKey.Int64(int64(op.SubscriptionID)))
+	}
+	if op.Endpoint != "" {
+		attrs = append(attrs, EndpointKey.String(op.Endpoint))
+	}
+	return attrs
+}
+
+// metricAttributes leaves out the ids so the number of series stays bounded.
+func metricAttributes(op *solanastreaming.Operation, err error) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{OperationKey.String(op.Name), OutcomeKey.String(outcome(err))}
+	if op.Method != "" {
+		attrs = append(attrs, MethodKey.String(op.Method))
+	}
+	return attrs
+}
+
+// outcome classifies err for the outcome attribute.
+func outcome(err error) string {
+	var serverErr *solanastreaming.ServerError
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	case errors.As(err, &serverErr):
+		return "server_error"
+	case errors.Is(err, solanastreaming.ErrRequestTimeout):
+		return "timeout"
+	}
+	return "error"
+}
@@ -0,0 +1,156 @@
+package solanastreamingotel_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingotel"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+func TestInstrumentation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	opts := []solanastreamingotel.Option{
+		solanastreamingotel.WithTracerProvider(tracerProvider),
+		solanastreamingotel.WithMeterProvider(meterProvider),
+	}
+
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := solanastreaming.New("test-key",
+		solanastreaming.WithHost(srv.URL()),
+		solanastreaming.WithInstrumentation(solanastreamingotel.NewInstrumentation(opts...)),
+	)
+	registration, err := solanastreamingotel.ObserveClient(cli, opts...)
+	if err != nil {
+		t.Fatalf("failed to observe client: %v", err)
+	}
+	defer registration.Unregister()
+
+	ctx, parent := tracerProvider.Tracer("test").Start(ctx, "strategy")
+	if err := cli.Connect(ctx); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer cli.Close()
+	sub, err := cli.SubscribeSwaps(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	srv.PushSwap(solanastreaming.SwapNotification{Slot: 1})
+	if _, err := sub.Receive(ctx); err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+	if err := sub.UpdateParams(ctx, &solanastreaming.SwapSubscribeParams{}); err != nil {
+		t.Fatalf("failed to update params: %v", err)
+	}
+	subscriptionID := sub.ID()
+
+	if err := sub.Unsubscribe(ctx); err != nil {
+		t.Fatalf("failed to unsubscribe: %v", err)
+	}
+	// the counters keep what the ended subscription received
+	var metrics metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &metrics); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	srv.FailNext("newPairSubscribe", -32602, "invalid params")
+	if _, err := cli.SubscribeNewPairs(ctx, nil); err == nil {
+		t.Fatal("expected subscribe to fail")
+	}
+	parent.End()
+
+	spans := exporter.GetSpans()
+	byName := make(map[string][]tracetest.SpanStub)
+	for _, span := range spans {
+		byName[span.Name] = append(byName[span.Name], span)
+	}
+	for name, count := range map[string]int{
+		"solanastreaming.connect":       1,
+		"solanastreaming.subscribe":     2,
+		"solanastreaming.update_params": 1,
+		"solanastreaming.unsubscribe":   1,
+		"solanastreaming.request":       4,
+	} {
+		if len(byName[name]) != count {
+			t.Fatalf("got %d %s spans, want %d", len(byName[name]), name, count)
+		}
+	}
+
+	subscribe := byName["solanastreaming.subscribe"][0]
+	if subscribe.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("subscribe span is not a child of the context span")
+	}
+	assertAttributes(t, subscribe.Attributes,
+		solanastreamingotel.MethodKey.String("swapSubscribe"),
+		solanastreamingotel.SubscriptionIDKey.Int64(int64(subscriptionID)),
+		solanastreamingotel.OutcomeKey.String("ok"),
+	)
+	request := byName["solanastreaming.request"][0]
+	if request.Parent.SpanID() != subscribe.SpanContext.SpanID() {
+		t.Fatal("request span is not a child of the subscribe span")
+	}
+	assertAttributes(t, request.Attributes, solanastreamingotel.MethodKey.String("swapSubscribe"))
+	if !hasKey(request.Attributes, solanastreamingotel.RequestIDKey) {
+		t.Fatal("request span has no request id")
+	}
+	assertAttributes(t, byName["solanastreaming.subscribe"][1].Attributes,
+		solanastreamingotel.MethodKey.String("newPairSubscribe"),
+		solanastreamingotel.OutcomeKey.String("server_error"),
+	)
+
+	notifications := findMetric(t, metrics, "solanastreaming.notifications").Data.(metricdata.Sum[int64])
+	if len(notifications.DataPoints) != 1 || notifications.DataPoints[0].Value != 1 {
+		t.Fatalf("unexpected notifications: %+v", notifications.DataPoints)
+	}
+	if method, _ := notifications.DataPoints[0].Attributes.Value(solanastreamingotel.MethodKey); method.AsString() != "swapSubscribe" {
+		t.Fatalf("unexpected method attribute: %v", method)
+	}
+	if notifications.DataPoints[0].Attributes.HasValue(solanastreamingotel.SubscriptionIDKey) {
+		t.Fatal("subscription id attribute on a metric")
+	}
+	duration := findMetric(t, metrics, "solanastreaming.operation.duration").Data.(metricdata.Histogram[float64])
+	if len(duration.DataPoints) == 0 {
+		t.Fatal("no operation durations recorded")
+	}
+}
+
+func assertAttributes(t *testing.T, attrs []attribute.KeyValue, want ...attribute.KeyValue) {
+	t.Helper()
+	set := attribute.NewSet(attrs...)
+	for _, kv := range want {
+		if got, ok := set.Value(kv.Key); !ok || got != kv.Value {
+			t.Fatalf("attribute %s = %v, want %v", kv.Key, got.Emit(), kv.Value.Emit())
+		}
+	}
+}
+
+func hasKey(attrs []attribute.KeyValue, key attribute.Key) bool {
+	set := attribute.NewSet(attrs...)
+	return set.HasValue(key)
+}
+
+func findMetric(t *testing.T, metrics metricdata.ResourceMetrics, name string) metricdata.Metrics {
+	t.Helper()
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+	t.Fatalf("metric %s not found", name)
+	return metricdata.Metrics{}
+}
@@ -0,0 +1,153 @@
+// Package solanastreamingprom exports the statistics of a SolanaStreaming client as Prometheus metrics.
+// Register a Collector with an existing registry, every scrape reads a fresh snapshot from Client.Stats and
+// Client.MethodStats. Notification series are labelled by method only: subscription ids change on every reconnect
+// and would make the number of series grow without bound, so the per subscription counters stay on
+// Subscription.Stats.
+package solanastreamingprom
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+)
+
+// Option configures a Collector.
+type Option func(*config)
+
+type config struct {
+	namespace   string
+	constLabels prometheus.Labels
+}
+
+// WithNamespace changes the metric name prefix. Defaults to "solanastreaming".
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace
+	}
+}
+
+// WithConstLabels adds labels to every metric, e.g. to tell several clients apart in one registry.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(c *config) {
+		c.constLabels = labels
+	}
+}
+
+// Collector is a prometheus.Collector for a client, create it with NewCollector.
+type Collector struct {
+	client *solanastreaming.Client
+
+	state           *prometheus.Desc
+	reconnects      *prometheus.Desc
+	requests        *prometheus.Desc
+	requestTimeouts *prometheus.Desc
+	requestDuration *prometheus.Desc
+	pingRTT         *prometheus.Desc
+	unclaimed       *prometheus.Desc
+
+	notifications    *prometheus.Desc
+	bytes            *prometheus.Desc
+	decodeErrors     *prometheus.Desc
+	dropped          *prometheus.Desc
+	stale            *prometheus.Desc
+	buffered         *prometheus.Desc
+	lastNotification *prometheus.Desc
+	blockTimeLag     *prometheus.Desc
+}
+
+// NewCollector creates a collector for client.
+func NewCollector(client *solanastreaming.Client, opts ...Option) *Collector {
+	c := config{namespace: "solanastreaming"}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(c.namespace, "", name), help, labels, c.constLabels)
+	}
+	subscription := []string{"method"}
+	return &Collector{
+		client: client,
+
+		state:           desc("connection_state", "Connection state of the client, 1 for the current state.", "state"),
+		reconnects:      desc("reconnects_total", "Successful reconnects."),
+		requests:        desc("requests_total", "Requests sent that wait for a response, e.g. subscribe and unsubscribe."),
+		requestTimeouts: desc("request_timeouts_total", "Requests that got no response within the request timeout."),
+		requestDuration: desc("request_duration_seconds", "Time from sending a request to its response."),
+		pingRTT:         desc("ping_rtt_seconds", "Last measured ping round trip."),
+		unclaimed:       desc("unclaimed_notifications_total", "Notifications discarded because no subscription claimed them."),
+
+		notifications:    desc("notifications_total", "Notifications received.", subscription...),
+		bytes:            desc("notification_bytes_total", "Size of the received notification frames.", subscription...),
+		decodeErrors:     desc("decode_errors_total", "Notifications that could not be decoded.", subscription...),
+		dropped:          desc("dropped_total", "Notifications discarded by the overflow policy.", subscription...),
+		stale:            desc("stale_total", "Notifications skipped because they were sent under replaced params.", subscription...),
+		buffered:         desc("buffered", "Notifications waiting to be read.", subscription...),
+		lastNotification: desc("last_notification_timestamp_seconds", "Unix time of the last notification.", subscription...),
+		blockTimeLag:     desc("block_time_lag_seconds", "Time from the block time of a notification to its receipt.", subscription...),
+	}
+}
+
+// Register creates a collector for client and registers it with registerer.
+func Register(registerer prometheus.Registerer, client *solanastreaming.Client, opts ...Option) (*Collector, error) {
+	c := NewCollector(client, opts...)
+	if err := registerer.Register(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		c.state, c.reconnects, c.requests, c.requestTimeouts, c.requestDuration, c.pingRTT, c.unclaimed,
+		c.notifications, c.bytes, c.decodeErrors, c.dropped, c.stale, c.buffered, c.lastNotification, c.blockTimeLag,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.Stats()
+	for _, state := range solanastreaming.ConnectionStates() {
+		value := 0.0
+		if state == stats.State {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.state, prometheus.GaugeValue, value, state.String())
+	}
+	ch <- prometheus.MustNewConstMetric(c.reconnects, prometheus.CounterValue, float64(stats.Reconnects))
+	ch <- prometheus.MustNewConstMetric(c.requests, prometheus.CounterValue, float64(stats.Requests))
+	ch <- prometheus.MustNewConstMetric(c.requestTimeouts, prometheus.CounterValue, float64(stats.RequestTimeouts))
+	ch <- histogram(c.requestDuration, stats.RequestLatency)
+	ch <- prometheus.MustNewConstMetric(c.pingRTT, prometheus.GaugeValue, stats.RTT.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.unclaimed, prometheus.CounterValue, float64(stats.Unclaimed))
+
+	for method, stats := range c.client.MethodStats() {
+		labels := []string{method}
+		ch <- prometheus.MustNewConstMetric(c.notifications, prometheus.CounterValue, float64(stats.Received), labels...)
+		ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.CounterValue, float64(stats.Bytes), labels...)
+		ch <- prometheus.MustNewConstMetric(c.decodeErrors, prometheus.CounterValue, float64(stats.DecodeErrors), labels...)
+		ch <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(stats.Dropped), labels...)
+		ch <- prometheus.MustNewConstMetric(c.stale, prometheus.CounterValue, float64(stats.Stale), labels...)
+		ch <- prometheus.MustNewConstMetric(c.buffered, prometheus.GaugeValue, float64(stats.Buffered), labels...)
+		if !stats.LastNotification.IsZero() {
+			ch <- prometheus.MustNewConstMetric(c.lastNotification, prometheus.GaugeValue, seconds(stats.LastNotification), labels...)
+		}
+		ch <- histogram(c.blockTimeLag, stats.Latency, labels...)
+	}
+}
+
+// histogram converts a latency snapshot into a Prometheus histogram in seconds.
+func histogram(desc *prometheus.Desc, stats solanastreaming.LatencyStats, labels ...string) prometheus.Metric {
+	buckets := make(map[float64]uint64, len(stats.Buckets))
+	for _, bucket := range stats.Buckets {
+		buckets[bucket.UpperBound.Seconds()] = bucket.Count
+	}
+	return prometheus.MustNewConstHistogram(desc, stats.Count, stats.Sum.Seconds(), buckets, labels...)
+}
+
+func seconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
@@ -0,0 +1,126 @@
+package solanastreamingprom_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingprom"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+func TestCollector(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := solanastreaming.New("test-key", solanastreaming.WithHost(srv.URL()))
+	if err := cli.Connect(ctx); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer cli.Close()
+
+	registry := prometheus.NewPedanticRegistry()
+	collector, err := solanastreamingprom.Register(registry, cli, solanastreamingprom.WithConstLabels(prometheus.Labels{"feed": "primary"}))
+	if err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+
+	// subscriptions with the same method share a series
+	var subs []*solanastreaming.SwapsSubscription
+	for range 2 {
+		sub, err := cli.SubscribeSwaps(ctx, nil)
+		if err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+		subs = append(subs, sub)
+	}
+	srv.PushSwap(solanastreaming.SwapNotification{Slot: 1, BlockTime: uint64(time.Now().Unix())})
+	srv.PushSwap(solanastreaming.SwapNotification{Slot: 2, BlockTime: uint64(time.Now().Unix())})
+	for _, sub := range subs {
+		for range 2 {
+			if _, err := sub.Receive(ctx); err != nil {
+				t.Fatalf("failed to receive: %v", err)
+			}
+		}
+	}
+
+	expected := `
+# HELP solanastreaming_connection_state Connection state of the client, 1 for the current state.
+# TYPE solanastreaming_connection_state gauge
+solanastreaming_connection_state{feed="primary",state="closed"} 0
+solanastreaming_connection_state{feed="primary",state="connected"} 1
+solanastreaming_connection_state{feed="primary",state="connecting"} 0
+solanastreaming_connection_state{feed="primary",state="disconnected"} 0
+solanastreaming_connection_state{feed="primary",state="failed"} 0
+solanastreaming_connection_state{feed="primary",state="reconnecting"} 0
+# HELP solanastreaming_notifications_total Notifications received.
+# TYPE solanastreaming_notifications_total counter
+solanastreaming_notifications_total{feed="primary",method="swapSubscribe"} 4
+# HELP solanastreaming_reconnects_total Successful reconnects.
+# TYPE solanastreaming_reconnects_total counter
+solanastreaming_reconnects_total{feed="primary"} 0
+# HELP solanastreaming_requests_total Requests sent that wait for a response, e.g. subscribe and unsubscribe.
+# TYPE solanastreaming_requests_total counter
+solanastreaming_requests_total{feed="primary"} 2
+`
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"solanastreaming_connection_state",
+		"solanastreaming_notifications_total",
+		"solanastreaming_reconnects_total",
+		"solanastreaming_requests_total",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count := testutil.CollectAndCount(collector, "solanastreaming_block_time_lag_seconds"); count != 1 {
+		t.Fatalf("unexpected block time lag series: %d", count)
+	}
+
+	// a reconnect changes the subscription ids but not the series
+	srv.Disconnect()
+	if err := srv.WaitFor(ctx, func() bool { return len(srv.Calls("swapSubscribe")) == 4 }); err != nil {
+		t.Fatalf("subscriptions not replayed: %v", err)
+	}
+	if count := testutil.CollectAndCount(collector, "solanastreaming_notifications_total"); count != 1 {
+		t.Fatalf("unexpected notification series after reconnect: %d", count)
+	}
+
+	// counters keep what the ended subscriptions received, once the replayed subscriptions have their new ids
+	for _, sub := range subs {
+		waitUntil(t, ctx, func() bool { return sub.ID() > 2 })
+		if err := sub.Unsubscribe(ctx); err != nil {
+			t.Fatalf("failed to unsubscribe: %v", err)
+		}
+	}
+	expected = `
+# HELP solanastreaming_notifications_total Notifications received.
+# TYPE solanastreaming_notifications_total counter
+solanastreaming_notifications_total{feed="primary",method="swapSubscribe"} 4
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "solanastreaming_notifications_total"); err != nil {
+		t.Fatal(err)
+	}
+	problems, err := testutil.CollectAndLint(collector)
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("lint: %v %v", err, problems)
+	}
+}
+
+func waitUntil(t *testing.T, ctx context.Context, cond func() bool) {
+	t.Helper()
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	for !cond() {
+		select {
+		case <-ctx.Done():
+			t.Fatal("condition not met in time")
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,408 @@
+// Package solanastreamingtest provides an in-process SolanaStreaming server for testing code that uses the client
+// without network access. It speaks the same JSON protocol as the real api, lets tests push notifications, inject
+// errors, delays and disconnects, and records the requests the client sent.
+package solanastreamingtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+)
+
+// Call is a request received by the server.
+type Call struct {
+	Method string
+	Params json.RawMessage
+	Time   time.Time
+}
+
+// Server is a mock SolanaStreaming websocket server, create it with NewServer.
+type Server struct {
+	srv      *httptest.Server
+	upgrader websocket.Upgrader
+
+	lock     sync.Mutex
+	changed  chan struct{} // closed and replaced whenever calls, connections or subscriptions change
+	conns    map[*serverConn]struct{}
+	subs     map[uint]*serverSubscription
+	nextID   uint
+	calls    []Call
+	delay    time.Duration
+	upgrade  time.Duration
+	failures map[string]*solanastreaming.ServerError
+	dropPing bool
+}
+
+type serverConn struct {
+	ws   *websocket.Conn
+	lock sync.Mutex // gorilla allows a single writer
+}
+
+type serverSubscription struct {
+	id     uint
+	method string
+	params json.RawMessage
+	conn   *serverConn
+}
+
+// notification methods sent with pushed notifications by subscribe method
+var notificationMethods = map[string]string{
+	"swapSubscribe":        "swapNotification",
+	"newPairSubscribe":     "newPairNotification",
+	"latestBlockSubscribe": "latestBlockNotification",
+}
+
+// unsubscribe methods by the subscribe method they end
+var unsubscribeMethods = map[string]string{
+	"swapUnsubscribe":        "swapSubscribe",
+	"newPairUnsubscribe":     "newPairSubscribe",
+	"latestBlockUnsubscribe": "latestBlockSubscribe",
+}
+
+// NewServer starts a mock server. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		changed:  make(chan struct{}),
+		conns:    make(map[*serverConn]struct{}),
+		subs:     make(map[uint]*serverSubscription),
+		failures: make(map[string]*solanastreaming.ServerError),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// URL returns the websocket url to pass to solanastreaming.WithHost.
+func (s *Server) URL() string {
+	return "ws" + strings.TrimPrefix(s.srv.URL, "http")
+}
+
+// Close disconnects every client and stops the server.
+func (s *Server) Close() {
+	s.Disconnect()
+	s.srv.Close()
+}
+
+// SetDelay delays every response by d.
+func (s *Server) SetDelay(d time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.delay = d
+}
+
+// SetHandshakeDelay delays the websocket handshake of new connections by d.
+func (s *Server) SetHandshakeDelay(d time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.upgrade = d
+}
+
+// DropPings makes the server ignore pings, like a peer that went away without closing the connection.
+func (s *Server) DropPings(drop bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.dropPing = drop
+}
+
+// FailNext makes the next request for method fail with the given error.
+func (s *Server) FailNext(method string, code int, message string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.failures[method] = &solanastreaming.ServerError{Code: code, Message: message}
+}
+
+// Disconnect drops every client connection without a close frame.
+func (s *Server) Disconnect() {
+	s.lock.Lock()
+	conns := make([]*serverConn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.lock.Unlock()
+	for _, c := range conns {
+		c.ws.Close()
+	}
+}
+
+// Connections returns the number of connected clients.
+func (s *Server) Connections() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.conns)
+}
+
+// Calls returns the requests received for method, or every request if method is empty.
+func (s *Server) Calls(method string) []Call {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var calls []Call
+	for _, call := range s.calls {
+		if method == "" || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Subscriptions returns the number of active subscriptions for a subscribe method, e.g. "swapSubscribe".
+func (s *Server) Subscriptions(method string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	count := 0
+	for _, sub := range s.subs {
+		if sub.method == method {
+			count++
+		}
+	}
+	return count
+}
+
+// SubscriptionParams returns the current params of the active subscriptions for a subscribe method,
+// including changes made with updateSubscriptionParams.
+func (s *Server) SubscriptionParams(method string) []json.RawMessage {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var params []json.RawMessage
+	for _, sub := range s.subs {
+		if sub.method == method {
+			params = append(params, sub.params)
+		}
+	}
+	return params
+}
+
+// WaitFor blocks until cond returns true or ctx is done. cond is checked whenever a client connects,
+// disconnects, sends a request or a subscription changes.
+func (s *Server) WaitFor(ctx context.Context, cond func() bool) error {
+	for {
+		s.lock.Lock()
+		changed := s.changed
+		s.lock.Unlock()
+		if cond() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// PushSwap sends a swap notification to every swap subscription and returns how many received it.
+func (s *Server) PushSwap(notification solanastreaming.SwapNotification) int {
+	return s.push("swapSubscribe", notification)
+}
+
+// PushNewPair sends a new pair notification to every new pair subscription and returns how many received it.
+func (s *Server) PushNewPair(notification solanastreaming.NewPairNotification) int {
+	return s.push("newPairSubscribe", notification)
+}
+
+// PushLatestBlock sends a latest block notification to every latest block subscription and returns how many received it.
+func (s *Server) PushLatestBlock(notification solanastreaming.LatestBlockNotification) int {
+	return s.push("latestBlockSubscribe", notification)
+}
+
+// PushRaw sends a raw frame to every connected client.
+func (s *Server) PushRaw(frame []byte) {
+	s.lock.Lock()
+	conns := make([]*serverConn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.lock.Unlock()
+	for _, c := range conns {
+		c.write(frame)
+	}
+}
+
+// InjectError sends an error frame that does not belong to any request or subscription to every connected client.
+func (s *Server) InjectError(code int, message string) {
+	frame, _ := json.Marshal(map[string]any{
+		"id":    0,
+		"error": map[string]any{"code": code, "message": message},
+	})
+	s.PushRaw(frame)
+}
+
+// InjectSubscriptionError sends an error frame to every subscription for a subscribe method.
+func (s *Server) InjectSubscriptionError(method string, code int, message string) {
+	for _, sub := range s.subscriptions(method) {
+		frame, _ := json.Marshal(map[string]any{
+			"id":              0,
+			"subscription_id": sub.id,
+			"error":           map[string]any{"code": code, "message": message},
+		})
+		sub.conn.write(frame)
+	}
+}
+
+func (s *Server) subscriptions(method string) []*serverSubscription {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var subs []*serverSubscription
+	for _, sub := range s.subs {
+		if sub.method == method {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+func (s *Server) push(method string, notification any) int {
+	params, err := json.Marshal(notification)
+	if err != nil {
+		panic(err)
+	}
+	count := 0
+	for _, sub := range s.subscriptions(method) {
+		frame, _ := json.Marshal(map[string]any{
+			"id":              0,
+			"subscription_id": sub.id,
+			"method":          notificationMethods[method],
+			"params":          json.RawMessage(params),
+		})
+		if sub.conn.write(frame) == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// notify wakes WaitFor. Must be called with lock held.
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	delay := s.upgrade
+	s.lock.Unlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &serverConn{ws: ws}
+	ws.SetPingHandler(func(payload string) error {
+		s.lock.Lock()
+		drop := s.dropPing
+		s.lock.Unlock()
+		if drop {
+			return nil
+		}
+		err := ws.WriteControl(websocket.PongMessage, []byte(payload), time.Now().Add(time.Second))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+	s.lock.Lock()
+	s.conns[c] = struct{}{}
+	s.notify()
+	s.lock.Unlock()
+
+	defer func() {
+		ws.Close()
+		s.lock.Lock()
+		delete(s.conns, c)
+		for id, sub := range s.subs {
+			if sub.conn == c {
+				delete(s.subs, id)
+			}
+		}
+		s.notify()
+		s.lock.Unlock()
+	}()
+
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		var request struct {
+			ID     int             `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if json.Unmarshal(data, &request) != nil {
+			continue
+		}
+		s.handle(c, request.ID, request.Method, request.Params)
+	}
+}
+
+// handle answers a single request.
+func (s *Server) handle(c *serverConn, requestID int, method string, params json.RawMessage) {
+	s.lock.Lock()
+	s.calls = append(s.calls, Call{Method: method, Params: params, Time: time.Now()})
+	delay := s.delay
+	failure := s.failures[method]
+	delete(s.failures, method)
+
+	var result any
+	switch {
+	case failure != nil:
+	case notificationMethods[method] != "":
+		s.nextID++
+		s.subs[s.nextID] = &serverSubscription{id: s.nextID, method: method, params: params, conn: c}
+		result = map[string]any{"message": "subscribed", "subscription_id": s.nextID}
+	case unsubscribeMethods[method] != "":
+		var request struct {
+			SubscriptionID uint `json:"subscription_id"`
+		}
+		json.Unmarshal(params, &request)
+		sub := s.subs[request.SubscriptionID]
+		if sub == nil || sub.conn != c || sub.method != unsubscribeMethods[method] {
+			failure = &solanastreaming.ServerError{Code: -32602, Message: "subscription not found"}
+			break
+		}
+		delete(s.subs, request.SubscriptionID)
+		result = map[string]any{"message": "unsubscribed"}
+	case method == "updateSubscriptionParams":
+		var request struct {
+			SubscriptionID uint            `json:"subscription_id"`
+			Params         json.RawMessage `json:"params"`
+		}
+		json.Unmarshal(params, &request)
+		sub := s.subs[request.SubscriptionID]
+		if sub == nil || sub.conn != c {
+			failure = &solanastreaming.ServerError{Code: -32602, Message: "subscription not found"}
+			break
+		}
+		sub.params = request.Params
+		result = map[string]any{"message": "updated"}
+	default:
+		failure = &solanastreaming.ServerError{Code: -32601, Message: "method not found"}
+	}
+	s.notify()
+	s.lock.Unlock()
+
+	response := map[string]any{"id": requestID}
+	if failure != nil {
+		response["error"] = map[string]any{"code": failure.Code, "message": failure.Message}
+	} else {
+		response["result"] = result
+	}
+	frame, _ := json.Marshal(response)
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	c.write(frame)
+}
+
+func (c *serverConn) write(frame []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.ws.WriteMessage(websocket.TextMessage, frame)
+}
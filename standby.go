@@ -0,0 +1,274 @@
+package solanastreaming
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// dedupeWindow is how many recent notifications a HotStandby remembers to drop duplicates.
+const dedupeWindow = 50000
+
+// HotStandby runs identical subscriptions on two or more clients, typically connected to different hosts,
+// and merges them into a single subscription that delivers whichever copy of a notification arrives first.
+type HotStandby struct {
+	clients []*Client
+	legs    []standbyLegStats
+}
+
+type standbyLegStats struct {
+	received atomic.Uint64
+	wins     atomic.Uint64
+}
+
+// StandbyStats describes how often a connection delivered a notification before the others.
+type StandbyStats struct {
+	Endpoint string
+	Received uint64  // notifications received on this connection
+	Wins     uint64  // notifications this connection delivered first
+	WinRate  float64 // Wins / Received
+}
+
+// NewHotStandby creates a HotStandby over the given clients. Each client keeps its own options, reconnect
+// policy and endpoints.
+func NewHotStandby(primary, secondary *Client, others ...*Client) *HotStandby {
+	clients := append([]*Client{primary, secondary}, others...)
+	return &HotStandby{
+		clients: clients,
+		legs:    make([]standbyLegStats, len(clients)),
+	}
+}
+
+// Connect connects every client.
+func (h *HotStandby) Connect(ctx context.Context) error {
+	for _, c := range h.clients {
+		err := c.Connect(ctx)
+		if err != nil {
+			h.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes every client.
+func (h *HotStandby) Close() error {
+	var errs []error
+	for _, c := range h.clients {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Stats returns the per connection delivery statistics across all subscriptions.
+func (h *HotStandby) Stats() []StandbyStats {
+	stats := make([]StandbyStats, len(h.clients))
+	for i, c := range h.clients {
+		received, wins := h.legs[i].received.Load(), h.legs[i].wins.Load()
+		stats[i] = StandbyStats{
+			Endpoint: c.Endpoint(),
+			Received: received,
+			Wins:     wins,
+		}
+		if received > 0 {
+			stats[i].WinRate = float64(wins) / float64(received)
+		}
+	}
+	return stats
+}
+
+// SubscribeSwaps subscribes on every client and returns a single subscription. Duplicate notifications are
+// recognised by signature, amm account and slot. The options apply to the subscription on each client.
+func (h *HotStandby) SubscribeSwaps(ctx context.Context, params *SwapSubscribeParams, opts ...SubscriptionOption) (*SwapsSubscription, error) {
+	legs := make([]*SwapsSubscription, 0, len(h.clients))
+	for _, c := range h.clients {
+		sub, err := c.SubscribeSwaps(ctx, params, opts...)
+		if err != nil {
+			for _, leg := range legs {
+				leg.Unsubscribe(ctx)
+			}
+			return nil, err
+		}
+		legs = append(legs, sub)
+	}
+
+	// the legs apply the overflow policy, the merged buffer only needs to hold what they forward
+	config := newSubscriptionConfig(opts)
+	merged := newActiveSubscription("swapSubscribe", nil, subscriptionConfig{bufferSize: config.bufferSize, dropStale: config.dropStale})
+	s := &standby{
+		legs:   make([]subscription[SwapNotification], len(legs)),
+		merged: merged,
+		seen:   make(map[standbyKey]struct{}, dedupeWindow),
+		order:  make([]standbyKey, 0, dedupeWindow),
+	}
+	for i, leg := range legs {
+		s.legs[i] = leg.sub
+	}
+	s.wg.Add(len(legs))
+	for i := range legs {
+		go s.forward(i, &h.legs[i])
+	}
+	go func() {
+		s.wg.Wait()
+		merged.close()
+	}()
+
+	return &SwapsSubscription{
+		sub:     newSubscription[SwapNotification](nil, merged),
+		standby: s,
+	}, nil
+}
+
+// standby merges the legs of a HotStandby subscription.
+type standby struct {
+	legs   []subscription[SwapNotification]
+	merged *activeSubscription
+	wg     sync.WaitGroup
+
+	lock  sync.Mutex
+	seen  map[standbyKey]struct{}
+	order []standbyKey // ring buffer of seen keys, oldest first once full
+	next  int
+}
+
+type standbyKey struct {
+	Signature  solana.Signature
+	AmmAccount solana.PublicKey
+	Slot       uint64
+}
+
+// forward copies notifications from a leg to the merged subscription, dropping the ones already delivered.
+func (s *standby) forward(leg int, stats *standbyLegStats) {
+	defer s.wg.Done()
+	sub := s.legs[leg]
+	for {
+		message, open, ok := sub.active.take()
+		if !ok {
+			select {
+			case <-sub.client.deadChan():
+				return
+			case message, open = <-sub.active.messages:
+			}
+		}
+		if !open {
+			return
+		}
+		stats.received.Add(1)
+
+		key, ok := notificationKey(message)
+		if ok && !s.first(key) {
+			message.release()
+			continue
+		}
+		stats.wins.Add(1)
+		// the consumer may stop reading, so give up once the leg or the merged subscription ends
+		select {
+		case s.merged.messages <- message:
+		case <-sub.active.done:
+			message.release()
+			return
+		case <-s.merged.done:
+			message.release()
+			return
+		case <-sub.client.deadChan():
+			message.release()
+			return
+		}
+	}
+}
+
+// first records key and reports whether it has not been seen before.
+func (s *standby) first(key standbyKey) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, seen := s.seen[key]; seen {
+		return false
+	}
+	if len(s.order) < dedupeWindow {
+		s.order = append(s.order, key)
+	} else {
+		delete(s.seen, s.order[s.next])
+		s.order[s.next] = key
+		s.next = (s.next + 1) % dedupeWindow
+	}
+	s.seen[key] = struct{}{}
+	return true
+}
+
+func (s *standby) unsubscribe(ctx context.Context) error {
+	var errs []error
+	for _, leg := range s.legs {
+		errs = append(errs, unsubscribe[SwapNotification](ctx, leg, "swapUnsubscribe"))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *standby) updateParams(ctx context.Context, params *json.RawMessage) error {
+	var errs []error
+	for _, leg := range s.legs {
+		errs = append(errs, updateParams[SwapNotification](ctx, leg, params))
+	}
+	err := errors.Join(errs...)
+	if err == nil {
+		// the legs are already on the new generation, so their notifications under the old params turn stale
+		s.merged.generation.Add(1)
+	}
+	return err
+}
+
+// notificationKey extracts the fields identifying a swap notification without decoding the rest of it. Messages
+// that can not be parsed are passed through so the error surfaces in Receive.
+func notificationKey(message *wireMessage) (standbyKey, bool) {
+	if message.Params == nil {
+		return standbyKey{}, false
+	}
+	var key standbyKey
+	r := jsonReader{data: *message.Params}
+	for i := 0; ; i++ {
+		name, ok, err := r.member(i)
+		if err != nil {
+			return standbyKey{}, false
+		}
+		if !ok {
+			return key, !key.Signature.IsZero()
+		}
+		switch string(name) {
+		case "slot":
+			key.Slot, err = r.uint64()
+		case "signature":
+			err = r.signature(&key.Signature)
+		case "swap":
+			err = r.ammAccount(&key.AmmAccount)
+		default:
+			err = r.skip()
+		}
+		if err != nil {
+			return standbyKey{}, false
+		}
+	}
+}
+
+// ammAccount reads the ammAccount of a swap and skips everything else.
+func (r *jsonReader) ammAccount(account *solana.PublicKey) error {
+	if r.null() {
+		return nil
+	}
+	for i := 0; ; i++ {
+		name, ok, err := r.member(i)
+		if err != nil || !ok {
+			return err
+		}
+		if string(name) == "ammAccount" {
+			err = r.publicKey(account)
+		} else {
+			err = r.skip()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package solanastreaming_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+// newTestStandby connects a HotStandby over one client per server and subscribes to swaps on every server.
+func newTestStandby(t *testing.T, ctx context.Context, servers []*solanastreamingtest.Server, opts ...solanastreaming.SubscriptionOption) (*solanastreaming.HotStandby, *solanastreaming.SwapsSubscription) {
+	t.Helper()
+	clients := make([]*solanastreaming.Client, len(servers))
+	for i, srv := range servers {
+		clients[i] = solanastreaming.New("test-key", solanastreaming.WithHost(srv.URL()))
+	}
+	standby := solanastreaming.NewHotStandby(clients[0], clients[1], clients[2:]...)
+	if err := standby.Connect(ctx); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { standby.Close() })
+	sub, err := standby.SubscribeSwaps(ctx, nil, opts...)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	for _, srv := range servers {
+		if srv.Subscriptions("swapSubscribe") != 1 {
+			t.Fatalf("expected a subscription on every server")
+		}
+	}
+	return standby, sub
+}
+
+func TestHotStandby(t *testing.T) {
+	ctx := testContext(t)
+	first := solanastreamingtest.NewServer()
+	defer first.Close()
+	second := solanastreamingtest.NewServer()
+	defer second.Close()
+	standby, sub := newTestStandby(t, ctx, []*solanastreamingtest.Server{first, second})
+
+	swap := func(signature byte, slot uint64) solanastreaming.SwapNotification {
+		return solanastreaming.SwapNotification{Slot: slot, Signature: solana.Signature{signature}}
+	}
+	receive := func(want solanastreaming.SwapNotification) {
+		t.Helper()
+		n, err := sub.Receive(ctx)
+		if err != nil || n.Signature != want.Signature || n.Slot != want.Slot {
+			t.Fatalf("expected %s at slot %d, got %+v, %v", want.Signature, want.Slot, n, err)
+		}
+	}
+	received := func(leg int, want uint64) {
+		t.Helper()
+		waitUntil(t, ctx, func() bool { return standby.Stats()[leg].Received == want })
+	}
+
+	// the first server wins a, the second one wins b
+	first.PushSwap(swap(1, 1))
+	receive(swap(1, 1))
+	second.PushSwap(swap(1, 1))
+	received(1, 1)
+	second.PushSwap(swap(2, 1))
+	receive(swap(2, 1))
+	first.PushSwap(swap(2, 1))
+	received(0, 2)
+	// only on one server, or the same signature in another slot, is delivered as well
+	second.PushSwap(swap(3, 1))
+	receive(swap(3, 1))
+	second.PushSwap(swap(1, 2))
+	receive(swap(1, 2))
+
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if n, err := sub.Receive(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("duplicate delivered: %+v, %v", n, err)
+	}
+
+	want := []solanastreaming.StandbyStats{
+		{Endpoint: first.URL(), Received: 2, Wins: 1, WinRate: 0.5},
+		{Endpoint: second.URL(), Received: 4, Wins: 3, WinRate: 0.75},
+	}
+	if stats := standby.Stats(); len(stats) != 2 || stats[0] != want[0] || stats[1] != want[1] {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats := sub.Stats(); stats.Received != 6 {
+		t.Fatalf("expected the legs to count 6 notifications, got %+v", stats)
+	}
+}
+
+func TestHotStandbyUnsubscribeWithoutReading(t *testing.T) {
+	ctx := testContext(t)
+	first := solanastreamingtest.NewServer()
+	defer first.Close()
+	second := solanastreamingtest.NewServer()
+	defer second.Close()
+	standby, sub := newTestStandby(t, ctx, []*solanastreamingtest.Server{first, second}, solanastreaming.WithSubscriptionBuffer(1))
+
+	// one notification fills the merged buffer, then both legs block forwarding the next one
+	for i := range 4 {
+		first.PushSwap(solanastreaming.SwapNotification{Signature: solana.Signature{1, byte(i)}})
+		second.PushSwap(solanastreaming.SwapNotification{Signature: solana.Signature{2, byte(i)}})
+	}
+	waitUntil(t, ctx, func() bool {
+		stats := standby.Stats()
+		return stats[0].Received+stats[1].Received == 3
+	})
+
+	if err := sub.Unsubscribe(ctx); err != nil {
+		t.Fatalf("failed to unsubscribe: %v", err)
+	}
+	select {
+	case <-sub.Done():
+	case <-ctx.Done():
+		t.Fatal("merged subscription not closed after unsubscribe")
+	}
+}
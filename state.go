@@ -0,0 +1,176 @@
+package solanastreaming
+
+import (
+	"time"
+)
+
+// ConnectionState describes where the client is in its connection lifecycle.
+type ConnectionState int
+
+const (
+	StateDisconnected ConnectionState = iota // Connect has not been called yet or the last dial failed
+	StateConnecting                          // Connect is dialing the server
+	StateConnected                           // the connection is up and messages are being received
+	StateReconnecting                        // the connection dropped and the client is trying to reconnect
+	StateFailed                              // the connection dropped and the client gave up reconnecting
+	StateClosed                              // Close was called
+)
+
+// ConnectionStates returns every ConnectionState in lifecycle order, e.g. to report one series per state.
+func ConnectionStates() []ConnectionState {
+	return []ConnectionState{StateDisconnected, StateConnecting, StateConnected, StateReconnecting, StateFailed, StateClosed}
+}
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateFailed:
+		return "failed"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// StateChangeEvent is passed to OnStateChange callbacks whenever the connection state changes.
+type StateChangeEvent struct {
+	From ConnectionState
+	To   ConnectionState
+	Err  error // the error that caused the change, nil for expected transitions
+	Time time.Time
+}
+
+// DisconnectEvent is passed to OnDisconnect callbacks when an established connection drops.
+type DisconnectEvent struct {
+	Err  error
+	Time time.Time
+}
+
+// ReconnectEvent is passed to OnReconnect callbacks once a dropped connection has been re-established.
+type ReconnectEvent struct {
+	Attempt        int       // number of dial attempts it took to reconnect
+	Err            error     // the error that dropped the previous connection
+	DisconnectedAt time.Time // when the previous connection dropped
+	Time           time.Time
+}
+
+// ResubscribeFailedEvent is passed to OnResubscribeFailed callbacks when a subscription could not be replayed after a reconnect.
+// The subscription is tried again on the next reconnect.
+type ResubscribeFailedEvent struct {
+	Method         string
+	SubscriptionID uint // the subscription id from before the reconnect
+	Err            error
+	Time           time.Time
+}
+
+type eventHooks struct {
+	stateChange       []func(StateChangeEvent)
+	disconnect        []func(DisconnectEvent)
+	reconnect         []func(ReconnectEvent)
+	resubscribeFailed []func(ResubscribeFailedEvent)
+	err               []func(error)
+}
+
+// State returns the current connection state.
+func (o *Client) State() ConnectionState {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	return o.state
+}
+
+// OnStateChange registers a callback for every connection state change.
+// Callbacks run on the goroutine that changed the state and should return quickly.
+func (o *Client) OnStateChange(fn func(StateChangeEvent)) {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	o.hooks.stateChange = append(o.hooks.stateChange, fn)
+}
+
+// OnDisconnect registers a callback for when an established connection drops.
+func (o *Client) OnDisconnect(fn func(DisconnectEvent)) {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	o.hooks.disconnect = append(o.hooks.disconnect, fn)
+}
+
+// OnReconnect registers a callback for when a dropped connection is re-established, before subscriptions are replayed.
+func (o *Client) OnReconnect(fn func(ReconnectEvent)) {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	o.hooks.reconnect = append(o.hooks.reconnect, fn)
+}
+
+// OnResubscribeFailed registers a callback for when a subscription could not be replayed after a reconnect.
+func (o *Client) OnResubscribeFailed(fn func(ResubscribeFailedEvent)) {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	o.hooks.resubscribeFailed = append(o.hooks.resubscribeFailed, fn)
+}
+
+// setState moves the client to state and notifies the OnStateChange callbacks.
+func (o *Client) setState(state ConnectionState, err error) {
+	o.stateLock.Lock()
+	from := o.state
+	o.state = state
+	hooks := o.hooks.stateChange
+	o.stateLock.Unlock()
+
+	if from == state {
+		return
+	}
+	event := StateChangeEvent{From: from, To: state, Err: err, Time: time.Now()}
+	for _, fn := range hooks {
+		fn(event)
+	}
+}
+
+func (o *Client) emitDisconnect(event DisconnectEvent) {
+	o.stateLock.Lock()
+	hooks := o.hooks.disconnect
+	o.stateLock.Unlock()
+	for _, fn := range hooks {
+		fn(event)
+	}
+}
+
+func (o *Client) emitReconnect(event ReconnectEvent) {
+	o.stateLock.Lock()
+	hooks := o.hooks.reconnect
+	o.stateLock.Unlock()
+	for _, fn := range hooks {
+		fn(event)
+	}
+}
+
+func (o *Client) emitResubscribeFailed(event ResubscribeFailedEvent) {
+	o.stateLock.Lock()
+	hooks := o.hooks.resubscribeFailed
+	o.stateLock.Unlock()
+	for _, fn := range hooks {
+		fn(event)
+	}
+}
+
+// OnError registers a callback for server errors that do not belong to a request or subscription.
+// The errors are of type *ServerError and do not affect the connection.
+func (o *Client) OnError(fn func(error)) {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	o.hooks.err = append(o.hooks.err, fn)
+}
+
+func (o *Client) emitError(err error) {
+	o.stateLock.Lock()
+	hooks := o.hooks.err
+	o.stateLock.Unlock()
+	for _, fn := range hooks {
+		fn(err)
+	}
+}
@@ -0,0 +1,301 @@
+package solanastreaming
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// blockTimeBounds are the upper bounds of the notification latency buckets. Block times have a resolution of one
+// second, so a notification latency is only known to within a second and the buckets start there.
+var blockTimeBounds = []time.Duration{
+	time.Second,
+	1500 * time.Millisecond,
+	2 * time.Second,
+	2500 * time.Millisecond,
+	3 * time.Second,
+	4 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+// requestBounds are the upper bounds of the request latency buckets, from a local round trip to the request timeout.
+var requestBounds = []time.Duration{
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// LatencyBucket counts the observations up to and including UpperBound. The counts are cumulative.
+type LatencyBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// LatencyStats is a snapshot of a latency histogram.
+type LatencyStats struct {
+	Count   uint64
+	Sum     time.Duration
+	Buckets []LatencyBucket // observations above the last bound are only included in Count
+	P50     time.Duration
+	P99     time.Duration
+}
+
+// Quantile estimates the q-quantile (0 <= q <= 1) by interpolating within the bucket it falls in.
+// Observations above the last bucket are reported as the last bound.
+func (s LatencyStats) Quantile(q float64) time.Duration {
+	if s.Count == 0 || len(s.Buckets) == 0 {
+		return 0
+	}
+	rank := q * float64(s.Count)
+	var lower time.Duration
+	var below uint64
+	for _, bucket := range s.Buckets {
+		if float64(bucket.Count) >= rank && bucket.Count > below {
+			fraction := (rank - float64(below)) / float64(bucket.Count-below)
+			return lower + time.Duration(fraction*float64(bucket.UpperBound-lower))
+		}
+		lower, below = bucket.UpperBound, bucket.Count
+	}
+	return lower
+}
+
+// merge adds the observations of other, both must use the same buckets.
+func (s LatencyStats) merge(other LatencyStats) LatencyStats {
+	if len(s.Buckets) == 0 {
+		s.Buckets = make([]LatencyBucket, len(other.Buckets))
+		for i, bucket := range other.Buckets {
+			s.Buckets[i].UpperBound = bucket.UpperBound
+		}
+	}
+	s.Count += other.Count
+	s.Sum += other.Sum
+	for i := range other.Buckets {
+		s.Buckets[i].Count += other.Buckets[i].Count
+	}
+	s.P50, s.P99 = s.Quantile(0.5), s.Quantile(0.99)
+	return s
+}
+
+// latencyHistogram records durations into buckets without locking.
+type latencyHistogram struct {
+	bounds []time.Duration
+	counts []atomic.Uint64 // one per bound plus the overflow bucket
+	count  atomic.Uint64
+	sum    atomic.Int64
+}
+
+func newLatencyHistogram(bounds []time.Duration) *latencyHistogram {
+	return &latencyHistogram{
+		bounds: bounds,
+		counts: make([]atomic.Uint64, len(bounds)+1),
+	}
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	d = max(d, 0)
+	i := 0
+	for i < len(h.bounds) && d > h.bounds[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.sum.Add(int64(d))
+	h.count.Add(1)
+}
+
+func (h *latencyHistogram) snapshot() LatencyStats {
+	stats := LatencyStats{
+		Buckets: make([]LatencyBucket, len(h.bounds)),
+		Sum:     time.Duration(h.sum.Load()),
+	}
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i].Load()
+		stats.Buckets[i] = LatencyBucket{UpperBound: bound, Count: cumulative}
+	}
+	stats.Count = cumulative + h.counts[len(h.bounds)].Load()
+	stats.P50, stats.P99 = stats.Quantile(0.5), stats.Quantile(0.99)
+	return stats
+}
+
+// blockTimer is implemented by notifications that carry the time of their block.
+type blockTimer interface {
+	blockTime() uint64
+}
+
+// notificationCounters count the notifications of a subscription, or of every subscription to a method.
+type notificationCounters struct {
+	received     atomic.Uint64
+	dropped      atomic.Uint64
+	stale        atomic.Uint64
+	bytes        atomic.Uint64
+	decodeErrors atomic.Uint64
+	lastReceived atomic.Int64 // unix nanoseconds
+	latency      *latencyHistogram
+}
+
+func newNotificationCounters() notificationCounters {
+	return notificationCounters{latency: newLatencyHistogram(blockTimeBounds)}
+}
+
+// snapshot returns the counters, Buffered is left to the caller.
+func (c *notificationCounters) snapshot() SubscriptionStats {
+	stats := SubscriptionStats{
+		Received:     c.received.Load(),
+		Bytes:        c.bytes.Load(),
+		DecodeErrors: c.decodeErrors.Load(),
+		Dropped:      c.dropped.Load(),
+		Stale:        c.stale.Load(),
+		Latency:      c.latency.snapshot(),
+	}
+	if last := c.lastReceived.Load(); last != 0 {
+		stats.LastNotification = time.Unix(0, last)
+	}
+	return stats
+}
+
+// countReceived counts a notification offered to the subscription, on the subscription and on its method.
+func (a *activeSubscription) countReceived(message *wireMessage) {
+	for _, c := range a.counters() {
+		c.received.Add(1)
+		c.bytes.Add(uint64(message.size))
+		c.lastReceived.Store(message.receivedAt.UnixNano())
+	}
+}
+
+func (a *activeSubscription) countDropped() {
+	for _, c := range a.counters() {
+		c.dropped.Add(1)
+	}
+}
+
+func (a *activeSubscription) countStale() {
+	for _, c := range a.counters() {
+		c.stale.Add(1)
+	}
+}
+
+func (a *activeSubscription) countDecodeError() {
+	for _, c := range a.counters() {
+		c.decodeErrors.Add(1)
+	}
+}
+
+// counters returns the counters of the subscription followed by the cumulative counters of its method, if any.
+func (a *activeSubscription) counters() []*notificationCounters {
+	if a.totals == nil {
+		return []*notificationCounters{&a.notificationCounters}
+	}
+	return []*notificationCounters{&a.notificationCounters, a.totals}
+}
+
+// observeLatency records how long after its block a notification was received. value is a pointer to the
+// notification so it is not copied.
+func (a *activeSubscription) observeLatency(value any, receivedAt time.Time) {
+	notification, ok := value.(blockTimer)
+	if !ok || notification.blockTime() == 0 || receivedAt.IsZero() {
+		return
+	}
+	latency := receivedAt.Sub(blockTimestamp(notification.blockTime()))
+	for _, c := range a.counters() {
+		c.latency.observe(latency)
+	}
+}
+
+// snapshot returns the counters of the subscription.
+func (a *activeSubscription) snapshot() SubscriptionStats {
+	stats := a.notificationCounters.snapshot()
+	stats.Buffered = a.buffered()
+	return stats
+}
+
+// Add sums the counters of other into s, keeping the latest notification time. Both must come from this package so
+// their latency buckets match.
+func (s SubscriptionStats) Add(other SubscriptionStats) SubscriptionStats {
+	s.Received += other.Received
+	s.Bytes += other.Bytes
+	s.DecodeErrors += other.DecodeErrors
+	s.Dropped += other.Dropped
+	s.Buffered += other.Buffered
+	s.Stale += other.Stale
+	if other.LastNotification.After(s.LastNotification) {
+		s.LastNotification = other.LastNotification
+	}
+	s.Latency = s.Latency.merge(other.Latency)
+	return s
+}
+
+// methodCounters returns the cumulative counters of method, creating them on first use.
+func (o *Client) methodCounters(method string) *notificationCounters {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	counters, ok := o.methods[method]
+	if !ok {
+		c := newNotificationCounters()
+		counters = &c
+		o.methods[method] = counters
+	}
+	return counters
+}
+
+// MethodStats returns the notification counters per subscription method. The counters are cumulative over every
+// subscription to the method, including the ones that ended, so unlike the Stats of a single subscription they
+// never go down on Unsubscribe or survive only until a reconnect changes the subscription id. Buffered is summed
+// over the active subscriptions.
+func (o *Client) MethodStats() map[string]SubscriptionStats {
+	o.stateLock.Lock()
+	defer o.stateLock.Unlock()
+	stats := make(map[string]SubscriptionStats, len(o.methods))
+	for method, counters := range o.methods {
+		stats[method] = counters.snapshot()
+	}
+	for a := range o.active {
+		s := stats[a.method]
+		s.Buffered += a.buffered()
+		stats[a.method] = s
+	}
+	return stats
+}
+
+// ClientStats is a snapshot of the connection counters and the notification counters summed over every method, see
+// MethodStats.
+type ClientStats struct {
+	State           ConnectionState
+	Reconnects      uint64        // successful reconnects
+	Requests        uint64        // sync requests sent, e.g. subscribe and unsubscribe
+	RequestTimeouts uint64        // sync requests that got no response within the request timeout
+	RequestLatency  LatencyStats  // time from sending a sync request to its response
+	RTT             time.Duration // last measured ping round trip
+	Unclaimed       uint64        // notifications discarded because no subscription claimed them
+	Subscriptions   int
+	Notifications   SubscriptionStats
+}
+
+// Stats returns a snapshot of the client counters.
+func (o *Client) Stats() ClientStats {
+	stats := ClientStats{
+		State:           o.State(),
+		Reconnects:      o.reconnects.Load(),
+		Requests:        o.requests.Load(),
+		RequestTimeouts: o.requestTimeouts.Load(),
+		RequestLatency:  o.requestLatency.snapshot(),
+		RTT:             o.RTT(),
+		Unclaimed:       o.UnclaimedNotifications(),
+	}
+	stats.Subscriptions = len(o.Subscriptions())
+	for _, method := range o.MethodStats() {
+		stats.Notifications = stats.Notifications.Add(method)
+	}
+	return stats
+}
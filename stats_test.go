@@ -0,0 +1,127 @@
+package solanastreaming_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	solanastreaming "github.com/solanastreaming/solanastreaming-client-go"
+	"github.com/solanastreaming/solanastreaming-client-go/solanastreamingtest"
+)
+
+func TestSubscriptionStats(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	sub, err := cli.SubscribeSwaps(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	blockTime := uint64(time.Now().Add(-3 * time.Second).Unix())
+	for slot := uint64(1); slot <= 4; slot++ {
+		srv.PushSwap(solanastreaming.SwapNotification{Slot: slot, BlockTime: blockTime})
+	}
+	srv.PushRaw([]byte(fmt.Sprintf(`{"id":0,"subscription_id":%d,"method":"swapNotification","params":{"slot":"bad"}}`, sub.ID())))
+	for range 4 {
+		if _, err := sub.Receive(ctx); err != nil {
+			t.Fatalf("failed to receive: %v", err)
+		}
+	}
+	if _, err := sub.Receive(ctx); err == nil {
+		t.Fatal("expected a decode error")
+	}
+
+	stats := sub.Stats()
+	if stats.Received != 5 || stats.DecodeErrors != 1 || stats.Bytes == 0 || stats.Buffered != 0 || stats.LastNotification.IsZero() {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	// block times are whole seconds, so the latency is somewhere between two and four seconds
+	if stats.Latency.Count != 4 || stats.Latency.P50 < time.Second || stats.Latency.P99 > 5*time.Second {
+		t.Fatalf("unexpected latency: %+v", stats.Latency)
+	}
+
+	clientStats := cli.Stats()
+	if clientStats.State != solanastreaming.StateConnected || clientStats.Requests != 1 || clientStats.RequestLatency.Count != 1 {
+		t.Fatalf("unexpected client stats: %+v", clientStats)
+	}
+	if clientStats.Subscriptions != 1 || clientStats.Notifications.Received != 5 || clientStats.Notifications.Latency.Count != 4 {
+		t.Fatalf("unexpected notification stats: %+v", clientStats.Notifications)
+	}
+}
+
+func TestMethodStatsSurviveUnsubscribe(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	for range 2 {
+		sub, err := cli.SubscribeSwaps(ctx, nil)
+		if err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+		srv.PushSwap(solanastreaming.SwapNotification{Slot: 1})
+		if _, err := sub.Receive(ctx); err != nil {
+			t.Fatalf("failed to receive: %v", err)
+		}
+		if err := sub.Unsubscribe(ctx); err != nil {
+			t.Fatalf("failed to unsubscribe: %v", err)
+		}
+	}
+
+	stats := cli.MethodStats()["swapSubscribe"]
+	if stats.Received != 2 || stats.Bytes == 0 || stats.Buffered != 0 {
+		t.Fatalf("unexpected method stats: %+v", stats)
+	}
+	clientStats := cli.Stats()
+	if clientStats.Subscriptions != 0 || clientStats.Notifications.Received != 2 {
+		t.Fatalf("unexpected client stats: %+v", clientStats)
+	}
+}
+
+func TestLatencyQuantile(t *testing.T) {
+	stats := solanastreaming.LatencyStats{
+		Count: 100,
+		Buckets: []solanastreaming.LatencyBucket{
+			{UpperBound: 100 * time.Millisecond, Count: 50},
+			{UpperBound: 200 * time.Millisecond, Count: 90},
+			{UpperBound: time.Second, Count: 98},
+		},
+	}
+	for _, tc := range []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0.25, 50 * time.Millisecond},
+		{0.5, 100 * time.Millisecond},
+		{0.7, 150 * time.Millisecond},
+		{0.99, time.Second},
+	} {
+		if got := stats.Quantile(tc.q); got != tc.want {
+			t.Errorf("Quantile(%v) = %s, want %s", tc.q, got, tc.want)
+		}
+	}
+}
+
+func TestRequestLatency(t *testing.T) {
+	ctx := testContext(t)
+	srv := solanastreamingtest.NewServer()
+	defer srv.Close()
+	cli := newTestClient(t, srv)
+
+	for range 20 {
+		if _, err := cli.SubscribeLatestBlock(ctx); err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+	}
+	// local requests take well under a millisecond, the buckets have to resolve that
+	stats := cli.Stats()
+	if latency := stats.RequestLatency; latency.Count != 20 || latency.P50 >= 10*time.Millisecond {
+		t.Fatalf("unexpected request latency: %+v", latency)
+	}
+	if buckets := stats.Notifications.Latency.Buckets; len(buckets) == 0 || buckets[0].UpperBound != time.Second {
+		t.Fatalf("unexpected notification latency buckets: %+v", buckets)
+	}
+}
@@ -7,37 +7,72 @@ import (
 )
 
 type subscription[T any] struct {
-	ID       uint
-	messages chan *wireMessage
-	client   *Client
+	active   *activeSubscription
+	client   *Client // nil for subscriptions merged from several clients
+	delivery *delivery[T]
+}
+
+func newSubscription[T any](client *Client, active *activeSubscription) subscription[T] {
+	return subscription[T]{
+		active:   active,
+		client:   client,
+		delivery: newDelivery[T](),
+	}
+}
+
+func (s subscription[T]) ID() uint {
+	s.client.lock.Lock()
+	defer s.client.lock.Unlock()
+	return s.active.id
 }
 
 func receive[T any](ctx context.Context, sub subscription[T]) (T, error) {
+	value, _, err := receiveWithInfo(ctx, sub)
+	return value, err
+}
+
+// decode unmarshals the params of a notification read from a subscription channel and releases the message.
+func decode[T any](a *activeSubscription, v *wireMessage, open bool) (T, error) {
 	var value T
-	if sub.client.generalErr != nil {
-		return value, sub.client.generalErr
-	}
-	select {
-	case <-ctx.Done():
-		return value, ctx.Err()
-	case v, open := <-sub.messages:
-		if !open {
-			return value, ErrSubscriptionClosed
-		}
-		if v.Params == nil {
-			return value, fmt.Errorf("received nil params in message: %v", v)
-		}
-		err := json.Unmarshal(*v.Params, &value)
-		if err != nil {
-			return value, fmt.Errorf("unmarshal error: %w", err)
-		}
-		return value, nil
+	if !open {
+		return value, a.closeErr()
+	}
+	defer v.release()
+	if v.Error != nil {
+		return value, newServerError(v)
 	}
+	if v.Params == nil {
+		a.countDecodeError()
+		return value, fmt.Errorf("received nil params in %s message", v.Method)
+	}
+	err := decodeNotification(*v.Params, &value)
+	if err != nil {
+		a.countDecodeError()
+		return value, fmt.Errorf("unmarshal error: %w", err)
+	}
+	a.observeLatency(&value, v.receivedAt)
+	return value, nil
 }
 
 func unsubscribe[T any](ctx context.Context, sub subscription[T], method string) error {
-	unsubscribeParams := []byte(fmt.Sprintf(`{"subscription_id":%d}`, sub.ID))
-	response, err := sub.client.sendSyncMessage(ctx, wireMessage{
+	err := sub.client.requestUnsubscribe(ctx, method, sub.active)
+	if err != nil {
+		return err
+	}
+	sub.client.dropSubscription(sub.active)
+	return nil
+}
+
+// requestUnsubscribe asks the server to end a subscription without closing it locally.
+func (o *Client) requestUnsubscribe(ctx context.Context, method string, a *activeSubscription) (err error) {
+	o.lock.Lock()
+	subscriptionID := a.id
+	o.lock.Unlock()
+	ctx, end := o.startOperation(ctx, &Operation{Name: OperationUnsubscribe, Method: method, SubscriptionID: subscriptionID})
+	defer func() { end(err) }()
+
+	unsubscribeParams := []byte(fmt.Sprintf(`{"subscription_id":%d}`, subscriptionID))
+	response, err := o.sendSyncMessage(ctx, wireMessage{
 		Method: method,
 		Params: (*json.RawMessage)(&unsubscribeParams),
 	})
@@ -47,31 +82,35 @@ func unsubscribe[T any](ctx context.Context, sub subscription[T], method string)
 
 	// could not subscribe
 	if response.Error != nil && response.Error.Code != 0 {
-		// o.log.Errorf("solana wss error: %d %s", val.Error.Code, val.Error.Message)
-		return fmt.Errorf("solana wss error: %d %s", response.Error.Code, response.Error.Message)
+		return newServerError(response)
 	}
-
-	close(sub.messages)
-
 	return nil
 }
 
-func updateParams[T any](ctx context.Context, sub subscription[T], params *json.RawMessage) error {
+func updateParams[T any](ctx context.Context, sub subscription[T], params *json.RawMessage) (err error) {
+	subscriptionID := sub.ID()
+	ctx, end := sub.client.startOperation(ctx, &Operation{Name: OperationUpdateParams, Method: sub.active.method, SubscriptionID: subscriptionID})
+	defer func() { end(err) }()
 
 	updateParams := struct {
 		SubscriptionID uint             `json:"subscription_id"`
 		Params         *json.RawMessage `json:"params"`
 	}{
-		SubscriptionID: sub.ID,
+		SubscriptionID: subscriptionID,
 		Params:         params,
 	}
 	data, err := json.Marshal(updateParams)
 	if err != nil {
 		return fmt.Errorf("marshal error: %w", err)
 	}
-	response, err := sub.client.sendSyncMessage(ctx, wireMessage{
+	response, err := sub.client.sendSyncMessageFunc(ctx, wireMessage{
 		Method: "updateSubscriptionParams",
 		Params: (*json.RawMessage)(&data),
+	}, func(response *wireMessage) {
+		// notifications read after this response are sent under the new params
+		if response.Error == nil || response.Error.Code == 0 {
+			sub.active.generation.Add(1)
+		}
 	})
 	if err != nil {
 		return err
@@ -79,30 +118,63 @@ func updateParams[T any](ctx context.Context, sub subscription[T], params *json.
 
 	// could not subscribe
 	if response.Error != nil && response.Error.Code != 0 {
-		// o.log.Errorf("solana wss error: %d %s", val.Error.Code, val.Error.Message)
-		return fmt.Errorf("solana wss error: %d %s", response.Error.Code, response.Error.Message)
+		return newServerError(response)
 	}
 
+	// remember the params so they are used when resubscribing after a reconnect
+	sub.client.lock.Lock()
+	sub.active.params = params
+	sub.client.lock.Unlock()
+
 	return nil
 }
 
-func (o *Client) subscribe(ctx context.Context, method string, params *json.RawMessage) (uint, chan *wireMessage, error) {
-	if o.generalErr != nil {
-		return 0, nil, o.generalErr
+func (o *Client) subscribe(ctx context.Context, method string, params *json.RawMessage, opts []SubscriptionOption) (_ *activeSubscription, err error) {
+	op := &Operation{Name: OperationSubscribe, Method: method}
+	ctx, end := o.startOperation(ctx, op)
+	defer func() { end(err) }()
+
+	if err := o.err(); err != nil {
+		return nil, err
 	}
 	// subscribe to pairs and wait to see if subscription is successful
+	subscriptionID, err := o.requestSubscription(ctx, method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// success: get subscription id from response and retup receiver, notifications that arrived first are held until now
+	op.SubscriptionID = subscriptionID
+	active := newActiveSubscription(method, params, newSubscriptionConfig(opts))
+	active.id = subscriptionID
+	active.totals = o.methodCounters(method)
+	o.lock.Lock()
+	overflowed := o.register(subscriptionID, active)
+	o.lock.Unlock()
+
+	o.stateLock.Lock()
+	o.active[active] = struct{}{}
+	o.stateLock.Unlock()
+	if overflowed {
+		o.slowConsumer(active)
+	}
+
+	return active, nil
+}
+
+// requestSubscription sends a subscribe request and returns the subscription id assigned by the server.
+func (o *Client) requestSubscription(ctx context.Context, method string, params *json.RawMessage) (uint, error) {
 	response, err := o.sendSyncMessage(ctx, wireMessage{
 		Method: method,
 		Params: params,
 	})
 	if err != nil {
-		return 0, nil, err
+		return 0, err
 	}
 
 	// could not subscribe
 	if response.Error != nil && response.Error.Code != 0 {
-		// o.log.Errorf("solana wss error: %d %s", val.Error.Code, val.Error.Message)
-		return 0, nil, fmt.Errorf("solana wss error: %d %s", response.Error.Code, response.Error.Message)
+		return 0, newServerError(response)
 	}
 
 	subscribeResponse := struct {
@@ -111,19 +183,7 @@ func (o *Client) subscribe(ctx context.Context, method string, params *json.RawM
 	}{}
 	err = json.Unmarshal(response.Result, &subscribeResponse)
 	if err != nil {
-		return 0, nil, err
+		return 0, err
 	}
-
-	// success: get subscription id from response and retup receiver
-	// todo: minor potential issue here is we setup receive after sending message we could miss a few messages?
-	receiverChan := make(chan *wireMessage, 1000) // internal buffer larger so we can still recive responses to sync messages while processing subscription messages
-	o.lock.Lock()
-	receiverKey := receiver{
-		Type:  receiverTypeBySubscriptionID,
-		Value: int(subscribeResponse.SubscriptionID),
-	}
-	o.receivers[receiverKey] = receiverChan
-	o.lock.Unlock()
-
-	return subscribeResponse.SubscriptionID, receiverChan, nil
+	return subscribeResponse.SubscriptionID, nil
 }
@@ -3,6 +3,7 @@ package solanastreaming
 import (
 	"context"
 	"encoding/json"
+	"iter"
 
 	"github.com/gagliardetto/solana-go"
 )
@@ -19,10 +20,11 @@ type FilterFields struct {
 }
 
 type SwapsSubscription struct {
-	sub subscription[SwapNotification]
+	sub     subscription[SwapNotification]
+	standby *standby // set when created by HotStandby.SubscribeSwaps
 }
 
-func (c *Client) SubscribeSwaps(ctx context.Context, params *SwapSubscribeParams) (*SwapsSubscription, error) {
+func (c *Client) SubscribeSwaps(ctx context.Context, params *SwapSubscribeParams, opts ...SubscriptionOption) (*SwapsSubscription, error) {
 
 	var input *json.RawMessage
 	if params != nil {
@@ -33,18 +35,16 @@ func (c *Client) SubscribeSwaps(ctx context.Context, params *SwapSubscribeParams
 		input = (*json.RawMessage)(&data)
 	}
 
-	subscriptionID, receiver, err := c.subscribe(ctx, "swapSubscribe", input)
+	active, err := c.subscribe(ctx, "swapSubscribe", input, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return &SwapsSubscription{
-		sub: subscription[SwapNotification]{
-			ID:       subscriptionID,
-			messages: receiver,
-			client:   c,
-		},
-	}, nil
+	sub := &SwapsSubscription{
+		sub: newSubscription[SwapNotification](c, active),
+	}
+	c.track(active, sub)
+	return sub, nil
 }
 
 func (s *SwapsSubscription) Receive(ctx context.Context) (SwapNotification, error) {
@@ -54,16 +54,116 @@ func (s *SwapsSubscription) Receive(ctx context.Context) (SwapNotification, erro
 	return receive[SwapNotification](ctx, s.sub)
 }
 
-// Unsubscribe from the swap notifications. To prevent deadlocks, Avoid putting your Unsubscribe() call in your Receive() loop
+// ReceiveWithInfo is Receive that also reports the params generation and arrival time of the notification.
+func (s *SwapsSubscription) ReceiveWithInfo(ctx context.Context) (SwapNotification, NotificationInfo, error) {
+	if s == nil {
+		return SwapNotification{}, NotificationInfo{}, ErrNoSubscription
+	}
+	return receiveWithInfo[SwapNotification](ctx, s.sub)
+}
+
+// All returns an iterator over the notifications, for use with range. It stops when ctx is done, after Unsubscribe
+// or after yielding the error that ended the connection.
+func (s *SwapsSubscription) All(ctx context.Context) iter.Seq2[SwapNotification, error] {
+	if s == nil {
+		return func(yield func(SwapNotification, error) bool) {
+			yield(SwapNotification{}, ErrNoSubscription)
+		}
+	}
+	return all(ctx, s.sub)
+}
+
+// Chan returns a channel of notifications that is closed when the subscription ends. Use Errors or Err for errors.
+// Chan, Errors and OnSwap share the same stream and should not be combined with Receive or All.
+func (s *SwapsSubscription) Chan() <-chan SwapNotification {
+	s.sub.delivery.start(s.sub)
+	return s.sub.delivery.ch
+}
+
+// Errors returns a channel of errors that is closed together with Chan. Errors are dropped if nobody reads them.
+func (s *SwapsSubscription) Errors() <-chan error {
+	s.sub.delivery.start(s.sub)
+	return s.sub.delivery.errs
+}
+
+// Err returns the error that closed Chan, or nil if it was closed by Unsubscribe.
+func (s *SwapsSubscription) Err() error {
+	return s.sub.delivery.Err()
+}
+
+// OnSwap calls handler for every swap notification until the subscription ends.
+// Swaps on the same amm account are handled in order unless WithUnordered is set.
+func (s *SwapsSubscription) OnSwap(handler func(SwapNotification), opts ...HandlerOption) {
+	s.sub.delivery.start(s.sub)
+	handle(s.sub.delivery, handler, func(n SwapNotification) uint64 { return accountKey(n.Swap.AmmAccount) }, opts)
+}
+
+// ID returns the server side subscription id, see Subscription.
+func (s *SwapsSubscription) ID() uint {
+	if s.standby != nil {
+		return s.standby.legs[0].ID()
+	}
+	return s.sub.ID()
+}
+
+// Method returns "swapSubscribe".
+func (s *SwapsSubscription) Method() string {
+	return s.sub.Method()
+}
+
+// Params returns the current subscription params.
+func (s *SwapsSubscription) Params() json.RawMessage {
+	if s.standby != nil {
+		return s.standby.legs[0].Params()
+	}
+	return s.sub.Params()
+}
+
+// Stats returns the subscription counters, summed over the connections of a HotStandby subscription.
+func (s *SwapsSubscription) Stats() SubscriptionStats {
+	if s.standby != nil {
+		// notifications are counted by the legs and decoded from the merged buffer
+		stats := s.sub.Stats()
+		for _, leg := range s.standby.legs {
+			stats = stats.Add(leg.Stats())
+		}
+		return stats
+	}
+	return s.sub.Stats()
+}
+
+// Done is closed when the subscription ends.
+func (s *SwapsSubscription) Done() <-chan struct{} {
+	return s.sub.Done()
+}
+
+// Dropped returns how many notifications were discarded by the overflow policy.
+func (s *SwapsSubscription) Dropped() uint64 {
+	return s.Stats().Dropped
+}
+
+// Unsubscribe from the swap notifications. It is safe to call from any goroutine, including a Receive loop.
 func (s *SwapsSubscription) Unsubscribe(ctx context.Context) error {
+	if s.standby != nil {
+		return s.standby.unsubscribe(ctx)
+	}
 	return unsubscribe[SwapNotification](ctx, s.sub, "swapUnsubscribe")
 }
 
-// UpdateParams change the subscription parameters. To prevent deadlocks, Avoid putting your UpdateParams() call in your Receive() loop
+// UpdateParams change the subscription parameters. It is safe to call from any goroutine, including a Receive loop.
+// Notifications sent under the old params are reported as stale by ReceiveWithInfo, or skipped with WithDropStale.
 func (s *SwapsSubscription) UpdateParams(ctx context.Context, params *SwapSubscribeParams) error {
 	data, err := json.Marshal(params)
 	if err != nil {
 		return err
 	}
+	if s.standby != nil {
+		return s.standby.updateParams(ctx, (*json.RawMessage)(&data))
+	}
 	return updateParams[SwapNotification](ctx, s.sub, (*json.RawMessage)(&data))
 }
+
+// Generation returns the params generation, incremented by every successful UpdateParams.
+func (s *SwapsSubscription) Generation() uint64 {
+	return s.sub.active.generation.Load()
+}
@@ -0,0 +1,168 @@
+package solanastreaming
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+// Transport opens connections to the server. The default is a WebsocketTransport built from the connection options,
+// set a different one with WithTransport, e.g. a ReplayTransport.
+type Transport interface {
+	Dial(ctx context.Context, url string, header http.Header) (Conn, error)
+}
+
+// Conn is a single connection opened by a Transport. ReadFrame is only called from one goroutine, the other methods
+// can be called concurrently with it.
+type Conn interface {
+	ReadFrame() ([]byte, error)
+	WriteFrame(frame []byte) error
+	// Ping blocks until the server answers or ctx is done and returns the round trip time. It relies on ReadFrame
+	// being called meanwhile.
+	Ping(ctx context.Context) (time.Duration, error)
+	// SetReadDeadline makes a blocked or future ReadFrame fail once t has passed. The zero time disables the deadline.
+	SetReadDeadline(t time.Time) error
+	Close() error
+}
+
+// CloseWriter is implemented by connections that can announce they are closing, such as websockets.
+// Shutdown uses it to let the server end the connection cleanly.
+type CloseWriter interface {
+	WriteClose(deadline time.Time) error
+}
+
+// WithTransport sets the transport used to connect. Options for the default websocket transport such as
+// WithDialer or WithProxy are ignored.
+func WithTransport(transport Transport) Option {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// WebsocketTransport connects with gorilla/websocket.
+type WebsocketTransport struct {
+	Dialer *websocket.Dialer // websocket.DefaultDialer if nil
+}
+
+func (t *WebsocketTransport) Dial(ctx context.Context, url string, header http.Header) (Conn, error) {
+	dialer := t.Dialer
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+	}
+	conn, resp, err := dialer.DialContext(ctx, url, header)
+	if err != nil {
+		var reason []byte
+		if resp != nil {
+			reason, _ = io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusTooManyRequests {
+				err = errors.Wrapf(ErrRateLimitExceeded, err.Error())
+			}
+		}
+		return nil, errors.Wrap(err, string(reason))
+	}
+	c := &websocketConn{
+		conn:  conn,
+		pings: make(map[string]chan struct{}),
+	}
+	conn.SetPongHandler(c.pong)
+	return c, nil
+}
+
+type websocketConn struct {
+	conn      *websocket.Conn
+	pingLock  sync.Mutex
+	pings     map[string]chan struct{} // waiting pings by payload
+	nextPing  uint64
+	writeLock sync.Mutex // gorilla allows a single concurrent writer
+}
+
+func (c *websocketConn) ReadFrame() ([]byte, error) {
+	_, frame, err := c.conn.ReadMessage()
+	return frame, err
+}
+
+// readFrameInto reads the next message into buf, growing it as needed, see frameReader.
+func (c *websocketConn) readFrameInto(buf []byte) ([]byte, error) {
+	_, r, err := c.conn.NextReader()
+	if err != nil {
+		return buf, err
+	}
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err != nil {
+			return buf, err
+		}
+	}
+}
+
+func (c *websocketConn) WriteFrame(frame []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	return c.conn.WriteMessage(websocket.TextMessage, frame)
+}
+
+func (c *websocketConn) Ping(ctx context.Context) (time.Duration, error) {
+	c.pingLock.Lock()
+	c.nextPing++
+	payload := strconv.FormatUint(c.nextPing, 10)
+	pong := make(chan struct{}, 1)
+	c.pings[payload] = pong
+	c.pingLock.Unlock()
+	defer func() {
+		c.pingLock.Lock()
+		delete(c.pings, payload)
+		c.pingLock.Unlock()
+	}()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(10 * time.Second)
+	}
+	sent := time.Now()
+	err := c.conn.WriteControl(websocket.PingMessage, []byte(payload), deadline)
+	if err != nil {
+		return 0, err
+	}
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-pong:
+		return time.Since(sent), nil
+	}
+}
+
+// pong is called by gorilla from ReadMessage when a pong arrives.
+func (c *websocketConn) pong(payload string) error {
+	c.pingLock.Lock()
+	defer c.pingLock.Unlock()
+	if pong, ok := c.pings[payload]; ok {
+		pong <- struct{}{}
+	}
+	return nil
+}
+
+func (c *websocketConn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *websocketConn) WriteClose(deadline time.Time) error {
+	return c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), deadline)
+}
+
+func (c *websocketConn) Close() error {
+	return c.conn.Close()
+}